@@ -0,0 +1,40 @@
+package idencoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodeWithAAD(t *testing.T) {
+	encoders := map[string]*IdEncoder{
+		"unkeyed": testEncoder(),
+		"keyed":   keyedEncoder("secret"),
+	}
+	tenantA, tenantB := []byte("tenant-a"), []byte("tenant-b")
+	for name, i := range encoders {
+		values := testValues()
+		rejected := 0
+		for _, n := range values {
+			code, err := i.EncodeWithAAD(n, tenantA, MinLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded, err := i.DecodeWithAAD(code, tenantA); err != nil || decoded != n {
+				t.Errorf("%s: DecodeWithAAD(%q, %q) = %d, %v, want %d", name, code, tenantA, decoded, err, n)
+			}
+			// the data is unchanged, so only the checksum can reject other AAD
+			_, err = i.DecodeWithAAD(code, tenantB)
+			switch {
+			case errors.Is(err, ErrChecksumMismatch):
+				rejected++
+			case err != nil:
+				t.Errorf("%s: DecodeWithAAD(%q, %q) error %v, want ErrChecksumMismatch", name, code, tenantB, err)
+			}
+		}
+		// the AAD shifts every checksum by the same amount, so other AAD is either
+		// rejected for every value or, about once in 29 pairs, for none
+		if rejected != len(values) {
+			t.Errorf("%s: other AAD rejected %d of %d codes, want all", name, rejected, len(values))
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package idencoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// allocRuns is the number of runs averaged when measuring allocations per operation
+const allocRuns = 100
+
+func TestAllocations(t *testing.T) {
+	i := testEncoder()
+	code, err := i.Encode(123456789, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := []byte(code)
+	buf := make([]byte, 0, 32)
+	var s string
+	var out uint64
+	// results are kept so the compiler can't elide the work being measured
+	tests := []struct {
+		name string
+		max  float64
+		f    func()
+	}{
+		{"Encode", 2, func() { s, _ = i.Encode(123456789, MinLength) }},
+		{"EncodeAppend", 0, func() { buf, _ = i.EncodeAppend(buf[:0], 123456789, MinLength) }},
+		{"Decode", 1, func() { out, _ = i.Decode(code) }},
+		{"DecodeInto", 0, func() { i.DecodeInto(raw, &out) }},
+	}
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(allocRuns, tt.f); allocs > tt.max {
+			t.Errorf("%s allocates %v times per run, want at most %v", tt.name, allocs, tt.max)
+		}
+	}
+	if s != code || out != 123456789 || string(buf) != code {
+		t.Errorf("got %q, %d, %q, want %q, 123456789, %q", s, out, buf, code, code)
+	}
+}
+
+// BenchmarkEncodePadding compares encoding a value that needs no padding with one
+// that does. Padding never formats strings, so it only allocates when the code
+// outgrows the buffer Encode starts with, and not at all with a reused buffer.
+func BenchmarkEncodePadding(b *testing.B) {
+	i := testEncoder()
+	const n = 1<<32 - 1
+	for _, bm := range []struct {
+		name      string
+		minLength uint64
+	}{
+		{"NoPad", 0},
+		{"Pad", 20},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for j := 0; j < b.N; j++ {
+				encodeSink, _ = i.Encode(n, bm.minLength)
+			}
+		})
+		b.Run(bm.name+"Append", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := make([]byte, 0, 32)
+			for j := 0; j < b.N; j++ {
+				buf, _ = i.EncodeAppend(buf[:0], n, bm.minLength)
+			}
+		})
+	}
+}
+
+var encodeSink string
+
+// BenchmarkDecodeInto decodes into a reused variable, which allocates nothing
+func BenchmarkDecodeInto(b *testing.B) {
+	i := testEncoder()
+	code, err := i.Encode(123456789, MinLength)
+	if err != nil {
+		b.Fatal(err)
+	}
+	raw := []byte(code)
+	b.ReportAllocs()
+	for j := 0; j < b.N; j++ {
+		if err := i.DecodeInto(raw, &decodeSink); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+var decodeSink uint64
+
+// Over-long input is rejected before it is copied, so rejecting it costs the same
+// whatever its length
+func TestDecodeRejectsLongInputWithoutAllocating(t *testing.T) {
+	configs := map[string]func(i *IdEncoder){
+		"plain":       func(i *IdEncoder) {},
+		"prefix":      func(i *IdEncoder) { i.Prefix = "id_" },
+		"trailing":    func(i *IdEncoder) { i.ChecksumPlacement = ChecksumTrailing },
+		"interleaved": func(i *IdEncoder) { i.ChecksumPlacement = ChecksumInterleaved },
+		"decoys":      func(i *IdEncoder) { i.DecoyPrefixLen = 2 },
+		"grouped":     func(i *IdEncoder) { i.GroupSize = 4 },
+		"fold case":   func(i *IdEncoder) { i.FoldCase = true },
+	}
+	for name, configure := range configs {
+		i := testEncoder()
+		configure(i)
+		for _, size := range []int{1 << 10, 1 << 20} {
+			s := i.Prefix + strings.Repeat(string(i.Alphabet[1]), size)
+			var err error
+			allocs := testing.AllocsPerRun(allocRuns, func() { _, err = i.Decode(s) })
+			if !errors.Is(err, ErrTooLong) {
+				t.Errorf("%s: Decode of %d characters error %v, want ErrTooLong", name, size, err)
+			}
+			if allocs > 0 {
+				t.Errorf("%s: Decode of %d characters allocates %v times, want none", name, size, allocs)
+			}
+		}
+	}
+}
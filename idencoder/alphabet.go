@@ -0,0 +1,293 @@
+package idencoder
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Shuffle returns a copy of the alphabet deterministically shuffled by seed.
+// The receiver is not modified.
+func (a Alphabet) Shuffle(seed int64) Alphabet {
+	return a.shuffle(rand.New(rand.NewSource(seed)))
+}
+
+func (a Alphabet) shuffle(r *rand.Rand) Alphabet {
+	shuffled := make(Alphabet, len(a))
+	copy(shuffled, a)
+	r.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+// Displacement returns the average distance of each character of the alphabet from
+// its position in original, ignoring characters not in original. A random shuffle
+// of n characters has an expected displacement of about n/3; a low displacement means
+// the alphabet is nearly in its original order.
+func (a Alphabet) Displacement(original Alphabet) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+	total := 0
+	for idx, c := range a {
+		if orig := bytes.IndexByte(original, c); orig >= 0 {
+			if orig > idx {
+				total += orig - idx
+			} else {
+				total += idx - orig
+			}
+		}
+	}
+	return float64(total) / float64(len(a))
+}
+
+// DefaultMinDisplacement is the fraction of the alphabet length that a generated
+// alphabet's Displacement must reach, well below the n/3 expected of a random shuffle
+const DefaultMinDisplacement = 0.125
+
+// maxShuffleAttempts bounds re-rolls when a minimum displacement can't be reached
+const maxShuffleAttempts = 100
+
+type alphabetConfig struct {
+	minDisplacement    float64
+	withoutConfusables bool
+}
+
+// AlphabetOption configures the generation of random alphabets
+type AlphabetOption func(*alphabetConfig)
+
+// WithMinDisplacement sets the fraction of the alphabet length that a generated
+// alphabet's Displacement from the characters it was shuffled from must reach.
+// Zero accepts any shuffle.
+func WithMinDisplacement(fraction float64) AlphabetOption {
+	return func(c *alphabetConfig) {
+		c.minDisplacement = fraction
+	}
+}
+
+// RandomAlphabetSeed returns the characters of DefaultAlphabet shuffled by seed.
+// The same seed always produces the same alphabet. Shuffles that leave the alphabet
+// nearly in order, with a Displacement below DefaultMinDisplacement (or the
+// WithMinDisplacement option) of its length, are rejected and shuffled again from
+// the same seed. If no shuffle reaches the threshold after repeated attempts, the
+// most displaced one is returned.
+func RandomAlphabetSeed(seed int64, opts ...AlphabetOption) Alphabet {
+	return RandomAlphabetFrom(DefaultAlphabet, seed, opts...)
+}
+
+// AlphabetMatchesSeed reports whether seed, with the given options, regenerates a
+// through RandomAlphabetSeed, so a deployment can store just the seed and verify
+// its alphabet against it
+func AlphabetMatchesSeed(a Alphabet, seed int64, opts ...AlphabetOption) bool {
+	return bytes.Equal(a, RandomAlphabetSeed(seed, opts...))
+}
+
+// RandomAlphabetFrom returns the distinct characters of charset shuffled by seed,
+// with the same displacement check as RandomAlphabetSeed. With the
+// WithoutConfusables option, charset is first passed through FilterConfusables.
+func RandomAlphabetFrom(charset string, seed int64, opts ...AlphabetOption) Alphabet {
+	c := alphabetConfig{minDisplacement: DefaultMinDisplacement}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.withoutConfusables {
+		charset = FilterConfusables(charset)
+	}
+	original := distinct(charset)
+	threshold := c.minDisplacement * float64(len(original))
+	r := rand.New(rand.NewSource(seed))
+	var best Alphabet
+	bestDisplacement := -1.0
+	for attempt := 0; attempt < maxShuffleAttempts; attempt++ {
+		shuffled := original.shuffle(r)
+		d := shuffled.Displacement(original)
+		if d >= threshold {
+			return shuffled
+		}
+		if d > bestDisplacement {
+			best, bestDisplacement = shuffled, d
+		}
+	}
+	return best
+}
+
+// RandomAlphabet returns the characters of DefaultAlphabet in a random order,
+// subject to the same displacement check as RandomAlphabetSeed
+func RandomAlphabet(opts ...AlphabetOption) Alphabet {
+	return RandomAlphabetSeed(time.Now().UnixNano(), opts...)
+}
+
+// WithoutConfusables removes visually confusable characters with FilterConfusables
+// before shuffling
+func WithoutConfusables() AlphabetOption {
+	return func(c *alphabetConfig) {
+		c.withoutConfusables = true
+	}
+}
+
+// confusables lists groups of characters that are easily mistaken for one another
+var confusables = []string{
+	"0OoDQ",
+	"1lIi|",
+	"2Zz",
+	"5Ss",
+	"6Gb",
+	"8B",
+	"9gq",
+	"uvUV",
+	"cC",
+	"kK",
+	"pP",
+	"wW",
+	"xX",
+	"yY",
+}
+
+// FilterConfusables returns charset with every character that is easily mistaken
+// for an earlier character of charset removed, such as 'O' after '0' or 'l' after
+// '1', so one representative of each group of confusable characters remains
+func FilterConfusables(charset string) string {
+	var kept []byte
+	used := make(map[int]bool)
+	for idx := 0; idx < len(charset); idx++ {
+		c := charset[idx]
+		group := -1
+		for g, members := range confusables {
+			if strings.IndexByte(members, c) >= 0 {
+				group = g
+				break
+			}
+		}
+		if group >= 0 {
+			if used[group] {
+				continue
+			}
+			used[group] = true
+		}
+		kept = append(kept, c)
+	}
+	return string(kept)
+}
+
+// PrimeLengthAlphabet shuffles the distinct characters of charset by seed and trims
+// the result to the largest prime length no greater than the number of characters,
+// since prime length alphabets give the best results
+func PrimeLengthAlphabet(charset string, seed int64) (Alphabet, error) {
+	shuffled := distinct(charset).Shuffle(seed)
+	for length := len(shuffled); length >= 2; length-- {
+		if isPrime(length) {
+			return shuffled[:length], nil
+		}
+	}
+	return nil, &IdEncoderError{
+		Message: "Charset too short for a prime length alphabet",
+	}
+}
+
+// distinct returns the characters of charset without repeats, in order of first appearance
+func distinct(charset string) Alphabet {
+	var chars Alphabet
+	for idx := 0; idx < len(charset); idx++ {
+		if bytes.IndexByte(chars, charset[idx]) < 0 {
+			chars = append(chars, charset[idx])
+		}
+	}
+	return chars
+}
+
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Transcode converts a value encoded with the from alphabet to the to alphabet by
+// substituting each character with the character at the same position in to.
+// This is only equivalent to decoding and re-encoding when the encoders differ
+// only by alphabet ordering, and the alphabets must be the same length.
+func Transcode(code string, from, to Alphabet) (string, error) {
+	if len(from) != len(to) {
+		return "", &IdEncoderError{
+			Message: "Alphabets differ in length",
+		}
+	}
+	result := make([]byte, len(code))
+	for idx := 0; idx < len(code); idx++ {
+		pos := bytes.IndexByte(from, code[idx])
+		if pos < 0 {
+			return "", &InvalidCharacterError{Index: idx, Char: code[idx]}
+		}
+		result[idx] = to[pos]
+	}
+	return string(result), nil
+}
+
+// Equal reports whether a and b contain the same characters in the same order
+func (a Alphabet) Equal(b Alphabet) bool {
+	return bytes.Equal(a, b)
+}
+
+// Diff describes how b differs from a, returning an empty string if they are equal.
+// A reordering of the same characters is reported explicitly, since it changes every
+// encoded value just as surely as different characters do.
+func (a Alphabet) Diff(b Alphabet) string {
+	if a.Equal(b) {
+		return ""
+	}
+	counts := make(map[byte]int)
+	for _, c := range a {
+		counts[c]++
+	}
+	for _, c := range b {
+		counts[c]--
+	}
+	var missing, extra []byte
+	for c, n := range counts {
+		for ; n > 0; n-- {
+			missing = append(missing, c)
+		}
+		for ; n < 0; n++ {
+			extra = append(extra, c)
+		}
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		for idx := range a {
+			if a[idx] != b[idx] {
+				return fmt.Sprintf("reordered: same characters, first difference at index %d (%q vs %q)", idx, a[idx], b[idx])
+			}
+		}
+	}
+	sort.Slice(missing, func(x, y int) bool { return missing[x] < missing[y] })
+	sort.Slice(extra, func(x, y int) bool { return extra[x] < extra[y] })
+	return fmt.Sprintf("different characters: missing %q, extra %q", missing, extra)
+}
+
+// DecodeTryAlphabets decodes s with each candidate alphabet in turn, returning the
+// value and the first alphabet whose checksum validates. It is meant for recovering
+// a misplaced alphabet from known codes; with a single checksum character, a wrong
+// alphabet passes about once in checksum attempts, so confirm the match against
+// further codes. Candidates that don't form a valid encoder are skipped.
+func DecodeTryAlphabets(s string, candidates []Alphabet, blockSize BlockSize, checksum Checksum) (value uint64, matched Alphabet, err error) {
+	for _, alphabet := range candidates {
+		i := &IdEncoder{Alphabet: alphabet, BlockSize: blockSize, Checksum: checksum}
+		if i.Validate() != nil {
+			continue
+		}
+		if value, err := i.Decode(s); err == nil {
+			return value, alphabet, nil
+		}
+	}
+	return 0, nil, &IdEncoderError{
+		Message: fmt.Sprintf("No candidate alphabet decodes %q", s),
+	}
+}
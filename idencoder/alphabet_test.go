@@ -0,0 +1,162 @@
+package idencoder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrimeLengthAlphabet(t *testing.T) {
+	tests := []struct {
+		charset string
+		length  int
+	}{
+		{"aabbccdd", 3},
+		{"abcdefgh", 7},
+		{DefaultAlphabet, 31},
+		{"0123456789abcdefghijklmnopqrstuvwxyz", 31},
+	}
+	for _, tt := range tests {
+		a, err := PrimeLengthAlphabet(tt.charset, 1)
+		if err != nil {
+			t.Fatalf("PrimeLengthAlphabet(%q): %v", tt.charset, err)
+		}
+		if len(a) != tt.length || !isPrime(len(a)) {
+			t.Errorf("PrimeLengthAlphabet(%q) = %q, want %d characters", tt.charset, a, tt.length)
+		}
+		for idx, c := range a {
+			if bytes.IndexByte([]byte(tt.charset), c) < 0 || bytes.IndexByte(a[:idx], c) >= 0 {
+				t.Errorf("PrimeLengthAlphabet(%q) = %q, which isn't a subset without repeats", tt.charset, a)
+				break
+			}
+		}
+		i := &IdEncoder{Alphabet: a, BlockSize: DefaultBlockSize, Checksum: Checksum(len(a))}
+		if err := i.Validate(); err != nil {
+			t.Errorf("PrimeLengthAlphabet(%q) = %q: %v", tt.charset, a, err)
+		}
+	}
+	if _, err := PrimeLengthAlphabet("aaaa", 1); err == nil {
+		t.Error("PrimeLengthAlphabet(\"aaaa\") succeeded with one distinct character")
+	}
+}
+
+func TestAlphabetEqualAndDiff(t *testing.T) {
+	a := Alphabet("abcdef")
+	tests := []struct {
+		name  string
+		b     Alphabet
+		equal bool
+		diff  string
+	}{
+		{"identical", Alphabet("abcdef"), true, ""},
+		{"reordered", Alphabet("abdcef"), false, "reordered"},
+		{"disjoint", Alphabet("uvwxyz"), false, "different characters"},
+		{"one changed", Alphabet("abcdeg"), false, "different characters"},
+	}
+	for _, tt := range tests {
+		if got := a.Equal(tt.b); got != tt.equal {
+			t.Errorf("%s: Equal(%q) = %t, want %t", tt.name, tt.b, got, tt.equal)
+		}
+		if got := a.Diff(tt.b); !strings.HasPrefix(got, tt.diff) || (tt.diff == "") != (got == "") {
+			t.Errorf("%s: Diff(%q) = %q, want it to start with %q", tt.name, tt.b, got, tt.diff)
+		}
+	}
+	if got := a.Diff(Alphabet("abdcef")); !strings.Contains(got, "index 2") {
+		t.Errorf("Diff(\"abdcef\") = %q, want the first difference at index 2", got)
+	}
+	if got := a.Diff(Alphabet("uvwxyz")); !strings.Contains(got, `"abcdef"`) || !strings.Contains(got, `"uvwxyz"`) {
+		t.Errorf("Diff(\"uvwxyz\") = %q, want the missing and extra characters", got)
+	}
+}
+
+func TestRandomAlphabetMinDisplacement(t *testing.T) {
+	sorted := Alphabet("0123456789abcdefghijklmnopqrstuvwxyz")
+	for _, fraction := range []float64{0, DefaultMinDisplacement, 0.25} {
+		for seed := int64(0); seed < 50; seed++ {
+			a := RandomAlphabetFrom(string(sorted), seed, WithMinDisplacement(fraction))
+			if got := a.Displacement(sorted); got < fraction*float64(len(sorted)) {
+				t.Errorf("RandomAlphabetFrom(seed %d, %v) = %q has displacement %v, want at least %v",
+					seed, fraction, a, got, fraction*float64(len(sorted)))
+			}
+			if len(a) != len(sorted) || a.Diff(sorted) != "" && !strings.HasPrefix(a.Diff(sorted), "reordered") {
+				t.Errorf("RandomAlphabetFrom(seed %d, %v) = %q isn't a permutation", seed, fraction, a)
+			}
+		}
+	}
+	a := RandomAlphabet()
+	if got, min := a.Displacement(Alphabet(DefaultAlphabet)), DefaultMinDisplacement*float64(len(a)); got < min {
+		t.Errorf("RandomAlphabet() = %q has displacement %v, want at least %v", a, got, min)
+	}
+	if got := sorted.Displacement(sorted); got != 0 {
+		t.Errorf("Displacement of an alphabet from itself = %v, want 0", got)
+	}
+	if got := Alphabet("dcba").Displacement(Alphabet("abcd")); got != 2 {
+		t.Errorf("Displacement(\"dcba\", \"abcd\") = %v, want 2", got)
+	}
+}
+
+func TestDecodeTryAlphabets(t *testing.T) {
+	base := Alphabet(DefaultAlphabet)
+	correct := base.Shuffle(42)
+	i := &IdEncoder{Alphabet: correct, BlockSize: DefaultBlockSize, Checksum: DefaultChecksum}
+	code, err := i.Encode(123456789, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a wrong alphabet passes the checksum about once in 29 tries; none of these do
+	candidates := []Alphabet{base, base.Shuffle(1), base.Shuffle(2), Alphabet("ab"), base.Shuffle(3), correct, base.Shuffle(4)}
+	value, matched, err := DecodeTryAlphabets(code, candidates, DefaultBlockSize, DefaultChecksum)
+	if err != nil || value != 123456789 || !matched.Equal(correct) {
+		t.Errorf("DecodeTryAlphabets(%q) = %d, %q, %v, want 123456789, %q", code, value, matched, err, correct)
+	}
+	if _, _, err := DecodeTryAlphabets(code, candidates[:5], DefaultBlockSize, DefaultChecksum); err == nil {
+		t.Errorf("DecodeTryAlphabets(%q) without the correct alphabet succeeded", code)
+	}
+}
+
+func TestFilterConfusables(t *testing.T) {
+	tests := []struct {
+		charset, want string
+	}{
+		{"0O1lI5S", "015"},
+		{"O0", "O"},
+		{"abcdefgh", "abcdefgh"},
+		{"1lIi|2Zz", "12"},
+		{"8B9gquvUV", "89u"},
+	}
+	for _, tt := range tests {
+		if got := FilterConfusables(tt.charset); got != tt.want {
+			t.Errorf("FilterConfusables(%q) = %q, want %q", tt.charset, got, tt.want)
+		}
+	}
+	const charset = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	filtered := FilterConfusables(charset)
+	for _, group := range confusables {
+		kept := 0
+		for idx := 0; idx < len(group); idx++ {
+			if strings.IndexByte(filtered, group[idx]) >= 0 {
+				kept++
+			}
+		}
+		if kept > 1 {
+			t.Errorf("FilterConfusables kept %d of %q", kept, group)
+		}
+	}
+	a := RandomAlphabetFrom(charset, 1, WithoutConfusables())
+	if len(a) != len(filtered) || a.Diff(Alphabet(filtered)) != "" && !strings.HasPrefix(a.Diff(Alphabet(filtered)), "reordered") {
+		t.Errorf("RandomAlphabetFrom with WithoutConfusables = %q, want a shuffle of %q", a, filtered)
+	}
+}
+
+func TestAlphabetMatchesSeed(t *testing.T) {
+	a := RandomAlphabetSeed(42)
+	if !AlphabetMatchesSeed(a, 42) {
+		t.Error("AlphabetMatchesSeed(RandomAlphabetSeed(42), 42) = false")
+	}
+	if AlphabetMatchesSeed(a, 43) {
+		t.Error("AlphabetMatchesSeed(RandomAlphabetSeed(42), 43) = true")
+	}
+	if !AlphabetMatchesSeed(RandomAlphabetSeed(42, WithoutConfusables()), 42, WithoutConfusables()) {
+		t.Error("AlphabetMatchesSeed with matching options = false")
+	}
+}
@@ -0,0 +1,190 @@
+package idencoder
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// CommonPrefixLen returns the number of leading characters shared by the encodings
+// of lo and hi. If every value in a range shares a prefix, that prefix may leak
+// information about the range. Returns 0 if either value can't be encoded.
+func (i *IdEncoder) CommonPrefixLen(lo, hi, minLength uint64) int {
+	a, err := i.Encode(lo, minLength)
+	if err != nil {
+		return 0
+	}
+	b, err := i.Encode(hi, minLength)
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// ChecksumCharset returns the distinct characters that can appear in the checksum
+// position. Unless using CheckOutput or a registered checksum function, only the
+// first Checksum characters of the alphabet are ever used.
+func (i *IdEncoder) ChecksumCharset() []byte {
+	limit := len(i.Alphabet)
+	if mode, fn := i.checksumAlgorithm(); fn == nil && mode != CheckOutput && uint64(i.Checksum) < uint64(limit) {
+		limit = int(i.Checksum)
+	}
+	var charset []byte
+	seen := make(map[byte]bool)
+	for _, c := range i.Alphabet[:limit] {
+		if !seen[c] {
+			seen[c] = true
+			charset = append(charset, c)
+		}
+	}
+	return charset
+}
+
+// AdjacentDistance returns the number of characters that differ between the encodings
+// of n and n+1, counting any difference in length as differing characters. A larger
+// distance means consecutive values look less related. Returns 0 if n is the largest
+// value or either value can't be encoded.
+func (i *IdEncoder) AdjacentDistance(n, minLength uint64) int {
+	if n == math.MaxUint64 {
+		return 0
+	}
+	a, err := i.Encode(n, minLength)
+	if err != nil {
+		return 0
+	}
+	b, err := i.Encode(n+1, minLength)
+	if err != nil {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	distance := len(b) - len(a)
+	for idx := 0; idx < len(a); idx++ {
+		if a[idx] != b[idx] {
+			distance++
+		}
+	}
+	return distance
+}
+
+// AverageAdjacentDistance returns the mean AdjacentDistance of every value in [lo, hi),
+// for comparing how well configurations obscure sequential values. Returns 0 if the
+// range is empty.
+func (i *IdEncoder) AverageAdjacentDistance(lo, hi, minLength uint64) float64 {
+	if hi <= lo {
+		return 0
+	}
+	total := 0
+	for n := lo; n < hi; n++ {
+		total += i.AdjacentDistance(n, minLength)
+	}
+	return float64(total) / float64(hi-lo)
+}
+
+// Explain returns a multi-line trace of decoding s: the checksum character and
+// whether it validated, the data characters, the integer they represent, that
+// integer unscrambled, and the decoded value. If only the checksum fails, the
+// explanation is returned along with ErrChecksumMismatch; any other decode error
+// is returned with an empty explanation.
+func (i *IdEncoder) Explain(s string) (string, error) {
+	decoded, err := i.Decode(s)
+	if err != nil && !errors.Is(err, ErrChecksumMismatch) {
+		return "", err
+	}
+	raw, _ := i.parse(s)
+	data := []byte(raw[1:])
+	debased, _ := i.debase(data)
+	unscrambled := i.unscramble(debased)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Code:        %q\n", s)
+	if raw != s {
+		fmt.Fprintf(&sb, "Normalized:  %q\n", raw)
+	}
+	if expected := i.checksum(decoded, data); expected != raw[0] {
+		fmt.Fprintf(&sb, "Checksum:    %q (mismatch, expected %q)\n", raw[0], expected)
+	} else {
+		fmt.Fprintf(&sb, "Checksum:    %q (OK)\n", raw[0])
+	}
+	fmt.Fprintf(&sb, "Data:        %q\n", data)
+	fmt.Fprintf(&sb, "Debased:     %d\n", debased)
+	fmt.Fprintf(&sb, "Unscrambled: %d\n", unscrambled)
+	if i.Salt != 0 {
+		fmt.Fprintf(&sb, "Salt:        %d\n", i.Salt)
+	}
+	fmt.Fprintf(&sb, "Decoded:     %d\n", decoded)
+	return sb.String(), err
+}
+
+// TypoNeighbors substitutes every other alphabet character at each position of s,
+// returning the values of the substitutions that still decode with a valid checksum
+// to a different value. Each is a single character typo that would be accepted as
+// the wrong value, so the fewer neighbors, the more the checksum protects against
+// typos. Returns nil if s can't be parsed.
+func (i *IdEncoder) TypoNeighbors(s string) []uint64 {
+	raw, err := i.parse(s)
+	if err != nil {
+		return nil
+	}
+	original, _ := i.decodeRaw([]byte(raw))
+	b := []byte(raw)
+	var neighbors []uint64
+	for idx := range b {
+		c := b[idx]
+		for _, sub := range i.Alphabet {
+			if sub == c {
+				continue
+			}
+			b[idx] = sub
+			if decoded, err := i.decodeRaw(b); err == nil && decoded != original {
+				neighbors = append(neighbors, decoded)
+			}
+		}
+		b[idx] = c
+	}
+	return neighbors
+}
+
+// UnshuffledBits returns the number of significant bits of n that lie above the
+// scramble block and so pass through unshuffled, making large consecutive values
+// partially predictable. Zero means all of n is shuffled; if the encoder's largest
+// values report more, increase BlockSize, for example to RecommendedBlockSize.
+// A scramble key shuffles every bit.
+func (i *IdEncoder) UnshuffledBits(n uint64) int {
+	switch {
+	case i.scrambleKey != nil || i.BlockSize >= 64:
+		return 0
+	case i.AlignBlock:
+		return bits.Len64(n / i.digitSpan())
+	}
+	return bits.Len64(n >> uint(i.BlockSize))
+}
+
+// ValuesWithChecksum returns up to limit values, from 0 upward, whose encodings with
+// the encoder's MinLength have the checksum character c. It shows how many values
+// share each checksum character, and so how coarse the checksum is. The search
+// stops after limit times the alphabet length values, which finds limit values for
+// CheckValue and CheckPadded, but may find fewer for the other modes and registered
+// functions, whose checksums needn't be evenly spread. Returns nil if c can't
+// appear as a checksum.
+func (i *IdEncoder) ValuesWithChecksum(c byte, limit int) []uint64 {
+	if limit <= 0 || strings.IndexByte(string(i.ChecksumCharset()), c) < 0 {
+		return nil
+	}
+	var found []uint64
+	var data []byte
+	end := uint64(limit) * uint64(len(i.Alphabet))
+	for n := uint64(0); n < end && len(found) < limit; n++ {
+		data = i.appendBase(data[:0], i.scramble(n^i.Salt), i.minLength())
+		if i.checksum(n, data) == c {
+			found = append(found, n)
+		}
+	}
+	return found
+}
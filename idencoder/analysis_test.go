@@ -0,0 +1,219 @@
+package idencoder
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommonPrefixLen(t *testing.T) {
+	i := testEncoder()
+	i.AlignBlock = true
+	i.ChecksumPlacement = ChecksumTrailing
+	span := i.digitSpan()
+	blockDigits := 0
+	for x := span; x > 1; x /= uint64(len(i.Alphabet)) {
+		blockDigits++
+	}
+	const width = 10
+	shared := width - blockDigits
+	if got := i.CommonPrefixLen(7*span, 7*span+span-1, width); got < shared {
+		t.Errorf("values sharing a block share %d leading characters, want at least %d", got, shared)
+	}
+	if got := i.CommonPrefixLen(1*span, 1<<63, width); got != 0 {
+		t.Errorf("values far apart share %d leading characters, want 0", got)
+	}
+	code, _ := i.Encode(42, width)
+	if got := i.CommonPrefixLen(42, 42, width); got != len(code) {
+		t.Errorf("a value shares %d leading characters with itself, want %d", got, len(code))
+	}
+	if got := i.CommonPrefixLen(0, 1, DefaultMaxLength+1); got != 0 {
+		t.Errorf("unencodable values share %d leading characters, want 0", got)
+	}
+}
+
+func TestChecksumCharset(t *testing.T) {
+	for _, checksum := range []Checksum{7, Checksum(len(DefaultAlphabet))} {
+		i := testEncoder()
+		i.Checksum = checksum
+		charset := i.ChecksumCharset()
+		if string(charset) != DefaultAlphabet[:checksum] {
+			t.Errorf("Checksum %d: charset %q, want %q", checksum, charset, DefaultAlphabet[:checksum])
+		}
+		used := make(map[byte]bool)
+		for n := uint64(0); n < 1000; n++ {
+			code, _ := i.Encode(n, MinLength)
+			used[code[0]] = true
+		}
+		if len(used) != len(charset) {
+			t.Errorf("Checksum %d: %d checksum characters used, want %d", checksum, len(used), len(charset))
+		}
+		for c := range used {
+			if !bytes.Contains(charset, []byte{c}) {
+				t.Errorf("Checksum %d: checksum %q isn't in the charset", checksum, c)
+			}
+		}
+	}
+}
+
+func TestAdjacentDistance(t *testing.T) {
+	// with no scramble, a decimal alphabet and Checksum 10, codes are n mod 10
+	// followed by n in decimal
+	i := testEncoder()
+	i.Alphabet = Alphabet("0123456789")
+	i.BlockSize = 0
+	i.Checksum = 10
+	tests := []struct {
+		n, minLength uint64
+		want         int
+	}{
+		{1, 3, 2},  // "1001" and "2002"
+		{9, 3, 3},  // "9009" and "0010"
+		{9, 0, 3},  // "99" and "010"
+		{99, 3, 4}, // "9099" and "0100"
+		{1<<64 - 1, 3, 0},
+	}
+	for _, tt := range tests {
+		if got := i.AdjacentDistance(tt.n, tt.minLength); got != tt.want {
+			t.Errorf("AdjacentDistance(%d, %d) = %d, want %d", tt.n, tt.minLength, got, tt.want)
+		}
+	}
+	if got := i.AverageAdjacentDistance(8, 10, 3); got != 2.5 {
+		t.Errorf("AverageAdjacentDistance(8, 10, 3) = %v, want 2.5", got)
+	}
+	if got := i.AverageAdjacentDistance(10, 10, 3); got != 0 {
+		t.Errorf("AverageAdjacentDistance of an empty range = %v, want 0", got)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	i := testEncoder()
+	i.Alphabet = Alphabet("0123456789")
+	i.BlockSize = 4
+	i.Checksum = 10
+	// 42 is 0b101010, whose low 4 bits reverse to 0b100101, or 37
+	code, err := i.Encode(42, 3)
+	if err != nil || code != "2037" {
+		t.Fatalf("Encode(42, 3) = %q, %v, want \"2037\"", code, err)
+	}
+	explanation, err := i.Explain(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`Checksum:    '2' (OK)`,
+		`Data:        "037"`,
+		`Debased:     37`,
+		`Unscrambled: 42`,
+		`Decoded:     42`,
+	} {
+		if !strings.Contains(explanation, want+"\n") {
+			t.Errorf("Explain(%q) = %q, missing %q", code, explanation, want)
+		}
+	}
+	explanation, err = i.Explain("3037")
+	if !errors.Is(err, ErrChecksumMismatch) || !strings.Contains(explanation, `'3' (mismatch, expected '2')`) {
+		t.Errorf("Explain(\"3037\") = %q, %v, want a checksum mismatch expecting '2'", explanation, err)
+	}
+	if explanation, err := i.Explain("2x37"); err == nil || explanation != "" {
+		t.Errorf("Explain(\"2x37\") = %q, %v, want only an error", explanation, err)
+	}
+}
+
+func TestTypoNeighbors(t *testing.T) {
+	// With no scramble, 5 encodes to the data "11" in base 4, and CheckValue's
+	// checksum is 5 modulo Checksum. A typo in the checksum itself never validates.
+	i := testEncoder()
+	i.Alphabet = Alphabet("0123")
+	i.BlockSize = 0
+	tests := []struct {
+		checksum Checksum
+		want     []uint64
+	}{
+		// a typo must keep n odd: any high digit ("01", "21", "31"), but only
+		// 3 as the low digit ("13")
+		{2, []uint64{1, 9, 13, 7}},
+		// the checksum divides the radix, so it only checks the low digit
+		{4, []uint64{1, 9, 13}},
+		// 3 shares no factor with 4, so every single digit typo changes n modulo 3:
+		// 1, 9, 13, 4, 6 and 7 are all 0 or 1 modulo 3, and 5 is 2
+		{3, nil},
+	}
+	for _, tt := range tests {
+		i.Checksum = tt.checksum
+		code, err := i.Encode(5, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := i.TypoNeighbors(code); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Checksum %d: TypoNeighbors(%q) = %v, want %v", tt.checksum, code, got, tt.want)
+		}
+	}
+	if got := i.TypoNeighbors("!"); got != nil {
+		t.Errorf("TypoNeighbors(\"!\") = %v, want nil", got)
+	}
+}
+
+func TestUnshuffledBits(t *testing.T) {
+	i := testEncoder()
+	tests := []struct {
+		n    uint64
+		want int
+	}{
+		{0, 0},
+		{1<<24 - 1, 0}, // the largest value within the 24-bit block
+		{1 << 24, 1},
+		{1<<32 - 1, 8},
+		{1<<64 - 1, 40},
+	}
+	for _, tt := range tests {
+		if got := i.UnshuffledBits(tt.n); got != tt.want {
+			t.Errorf("UnshuffledBits(%#x) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+	i.BlockSize = BlockSize(RecommendedBlockSize(1<<40 - 1))
+	if got := i.UnshuffledBits(1<<40 - 1); got != 0 {
+		t.Errorf("UnshuffledBits with RecommendedBlockSize = %d, want 0", got)
+	}
+	if got := keyedEncoder("secret").UnshuffledBits(1<<64 - 1); got != 0 {
+		t.Errorf("UnshuffledBits with a scramble key = %d, want 0", got)
+	}
+	aligned := testEncoder()
+	aligned.AlignBlock = true
+	span := aligned.digitSpan()
+	if got := aligned.UnshuffledBits(span - 1); got != 0 {
+		t.Errorf("AlignBlock UnshuffledBits(%d) = %d, want 0", span-1, got)
+	}
+	if got := aligned.UnshuffledBits(span); got != 1 {
+		t.Errorf("AlignBlock UnshuffledBits(%d) = %d, want 1", span, got)
+	}
+}
+
+func TestValuesWithChecksum(t *testing.T) {
+	for _, mode := range []ChecksumMode{CheckValue, CheckPadded, CheckScrambled, CheckOutput} {
+		i := testEncoder()
+		i.ChecksumMode = mode
+		i.MinLength = 7
+		for _, c := range i.ChecksumCharset() {
+			values := i.ValuesWithChecksum(c, 5)
+			if (mode == CheckValue || mode == CheckPadded) && len(values) != 5 {
+				t.Errorf("mode %d: ValuesWithChecksum(%q, 5) = %d, want 5 values", mode, c, values)
+			}
+			for _, n := range values {
+				code, err := i.Encode(n, i.MinLength)
+				if err != nil || code[0] != c {
+					t.Errorf("mode %d: ValuesWithChecksum(%q) returned %d, encoded %q, %v", mode, c, n, code, err)
+				}
+			}
+		}
+	}
+	i := testEncoder()
+	if values := i.ValuesWithChecksum(i.Alphabet[len(i.Alphabet)-1], 5); values != nil {
+		t.Errorf("ValuesWithChecksum of a character beyond Checksum = %d, want nil", values)
+	}
+	if values := i.ValuesWithChecksum(i.Alphabet[0], 0); values != nil {
+		t.Errorf("ValuesWithChecksum with limit 0 = %d, want nil", values)
+	}
+}
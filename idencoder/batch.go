@@ -0,0 +1,95 @@
+package idencoder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DecodeError reports which input of a batch failed to decode
+type DecodeError struct {
+	Index int
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("IdEncoder error: value %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying decode error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError collects the errors of every input in a batch that failed to decode
+type BatchError []*DecodeError
+
+func (e BatchError) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("IdEncoder error: %d values failed to decode, first at %d: %v", len(e), e[0].Index, e[0].Err)
+}
+
+// DecodeManyLenient decodes every string in ss, continuing past failures. The returned
+// values are aligned with ss, with 0 in place of each value that failed to decode.
+// failed lists the indexes of ss that failed, and err is a BatchError describing
+// each failure, or nil if every value decoded.
+func (i *IdEncoder) DecodeManyLenient(ss []string) (values []uint64, failed []int, err error) {
+	values = make([]uint64, len(ss))
+	var errs BatchError
+	for idx, s := range ss {
+		decoded, err := i.Decode(s)
+		if err != nil {
+			failed = append(failed, idx)
+			errs = append(errs, &DecodeError{Index: idx, Err: err})
+			continue
+		}
+		values[idx] = decoded
+	}
+	if len(errs) > 0 {
+		return values, failed, errs
+	}
+	return values, nil, nil
+}
+
+// AuditResult describes whether a single code decodes cleanly
+type AuditResult struct {
+	Code  string
+	Valid bool
+	// Value is the decoded value, set for valid codes and those failing only
+	// the checksum
+	Value uint64
+	// Err is the reason the code is invalid, such as ErrChecksumMismatch,
+	// ErrInvalidCharacter or ErrOverflow, or nil if it is valid
+	Err error
+}
+
+// AuditCodes decodes every code, returning a result for each in the same order, so
+// an existing corpus of codes can be checked for corruption before it is trusted
+func (i *IdEncoder) AuditCodes(codes []string) []AuditResult {
+	results := make([]AuditResult, len(codes))
+	for idx, code := range codes {
+		decoded, err := i.Decode(code)
+		results[idx] = AuditResult{Code: code, Valid: err == nil, Err: err}
+		if err == nil || errors.Is(err, ErrChecksumMismatch) {
+			results[idx].Value = decoded
+		}
+	}
+	return results
+}
+
+// DecodeAppend decodes each of codes, appending the values to dst and returning
+// the extended slice, so a backing array can be reused across batches. Decoding
+// stops at the first code that fails, returning a *DecodeError with its index in
+// codes; the returned slice then holds dst followed by the values of the codes
+// before it.
+func (i *IdEncoder) DecodeAppend(dst []uint64, codes ...string) ([]uint64, error) {
+	for idx, code := range codes {
+		decoded, err := i.Decode(code)
+		if err != nil {
+			return dst, &DecodeError{Index: idx, Err: err}
+		}
+		dst = append(dst, decoded)
+	}
+	return dst, nil
+}
@@ -0,0 +1,108 @@
+package idencoder
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeManyLenient(t *testing.T) {
+	i := testEncoder()
+	good1, _ := i.Encode(1, MinLength)
+	good2, _ := i.Encode(2, MinLength)
+	ss := []string{good1, "", good2, good2[:2] + "!" + good2[3:], good1}
+	values, failed, err := i.DecodeManyLenient(ss)
+	if want := []uint64{1, 0, 2, 0, 1}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if want := []int{1, 3}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("failed = %v, want %v", failed, want)
+	}
+	var batch BatchError
+	if !errors.As(err, &batch) || len(batch) != 2 || batch[0].Index != 1 || batch[1].Index != 3 {
+		t.Fatalf("err = %v, want a BatchError for indexes 1 and 3", err)
+	}
+	if !errors.Is(batch[0], ErrTooShort) || !errors.Is(batch[1], ErrInvalidCharacter) {
+		t.Errorf("errors = %v, %v, want ErrTooShort and ErrInvalidCharacter", batch[0], batch[1])
+	}
+	values, failed, err = i.DecodeManyLenient([]string{good1, good2})
+	if err != nil || failed != nil || !reflect.DeepEqual(values, []uint64{1, 2}) {
+		t.Errorf("DecodeManyLenient of valid codes = %v, %v, %v", values, failed, err)
+	}
+}
+
+func TestAuditCodes(t *testing.T) {
+	i := testEncoder()
+	valid, _ := i.Encode(1000, MinLength)
+	tampered := string(i.Alphabet[(i.digit(valid[0])+1)%int(i.Checksum)]) + valid[1:]
+	// more data characters than a uint64 can hold
+	overflow := valid[:1] + strings.Repeat(string(i.Alphabet[len(i.Alphabet)-1]), 14)
+	results := i.AuditCodes([]string{valid, tampered, valid[:2] + "!" + valid[3:], overflow})
+	tests := []struct {
+		valid bool
+		value uint64
+		err   error
+	}{
+		{true, 1000, nil},
+		{false, 1000, ErrChecksumMismatch},
+		{false, 0, ErrInvalidCharacter},
+		{false, 0, ErrOverflow},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("AuditCodes returned %d results, want %d", len(results), len(tests))
+	}
+	for idx, tt := range tests {
+		r := results[idx]
+		if r.Valid != tt.valid || r.Value != tt.value || !errors.Is(r.Err, tt.err) {
+			t.Errorf("result %d = %+v, want Valid %t, Value %d, Err %v", idx, r, tt.valid, tt.value, tt.err)
+		}
+	}
+	if results[1].Code != tampered {
+		t.Errorf("result 1 has Code %q, want %q", results[1].Code, tampered)
+	}
+}
+
+func TestDecodeAppend(t *testing.T) {
+	i := testEncoder()
+	var codes []string
+	for _, n := range []uint64{10, 20, 30} {
+		code, _ := i.Encode(n, MinLength)
+		codes = append(codes, code)
+	}
+	dst := make([]uint64, 1, 8)
+	dst[0] = 7
+	got, err := i.DecodeAppend(dst, codes...)
+	if err != nil || !reflect.DeepEqual(got, []uint64{7, 10, 20, 30}) || &got[0] != &dst[0] {
+		t.Errorf("DecodeAppend = %v, %v, want [7 10 20 30] in dst's array", got, err)
+	}
+
+	// on failure, the values before the bad code are kept
+	bad := []string{codes[0], codes[1], "!" + codes[2][1:], codes[2]}
+	got, err = i.DecodeAppend(dst, bad...)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Index != 2 || !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("DecodeAppend error %v, want a DecodeError at index 2", err)
+	}
+	if !reflect.DeepEqual(got, []uint64{7, 10, 20}) {
+		t.Errorf("DecodeAppend after a failure = %v, want [7 10 20]", got)
+	}
+}
+
+// BenchmarkDecodeAppend decodes a page of codes into a reused slice, which never
+// grows; the remaining allocations are Decode's own, one per code
+func BenchmarkDecodeAppend(b *testing.B) {
+	i := testEncoder()
+	codes := make([]string, 100)
+	for idx := range codes {
+		codes[idx], _ = i.Encode(uint64(idx)*7919, MinLength)
+	}
+	dst := make([]uint64, 0, len(codes))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		var err error
+		if dst, err = i.DecodeAppend(dst[:0], codes...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package idencoder
+
+import "bytes"
+
+// EncodeToBytes converts an integer to width bytes for binary storage. Each byte is
+// the alphabet index of the corresponding character of the encoded value, checksum
+// first, with the data left-padded by zero bytes, so the most significant digit
+// comes first regardless of platform. Returns an error if the encoded value needs
+// more than width bytes.
+func (i *IdEncoder) EncodeToBytes(n uint64, width int) ([]byte, error) {
+	if width < 2 {
+		return nil, &IdEncoderError{
+			Message: "Width must allow for a checksum and at least one data byte",
+		}
+	}
+	data := i.enbase(i.scramble(n^i.Salt), uint64(width-1))
+	if len(data) > width-1 {
+		return nil, &IdEncoderError{
+			Message: "Encoded value exceeds width",
+		}
+	}
+	raw := string(i.checksum(n, []byte(data))) + data
+	b := make([]byte, len(raw))
+	for idx := 0; idx < len(raw); idx++ {
+		// a PadChar or ZeroSymbol outside the alphabet stands for the digit zero
+		if digit := bytes.IndexByte(i.Alphabet, raw[idx]); digit > 0 {
+			b[idx] = byte(digit)
+		}
+	}
+	return b, nil
+}
+
+// DecodeFromBytes converts bytes produced by EncodeToBytes back to an integer
+func (i *IdEncoder) DecodeFromBytes(b []byte) (uint64, error) {
+	raw := make([]byte, len(b))
+	for idx, digit := range b {
+		if int(digit) >= len(i.Alphabet) {
+			return 0, &InvalidCharacterError{Index: idx, Char: digit}
+		}
+		raw[idx] = i.Alphabet[digit]
+	}
+	return i.decodeRaw(raw)
+}
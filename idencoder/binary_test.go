@@ -0,0 +1,46 @@
+package idencoder
+
+import "testing"
+
+func TestEncodeToBytesBoundary(t *testing.T) {
+	i := testEncoder()
+	i.BlockSize = 0
+	// three bytes hold the checksum and two base-31 digits
+	largest := uint64(len(i.Alphabet)*len(i.Alphabet) - 1)
+	for _, n := range []uint64{0, 1, largest} {
+		b, err := i.EncodeToBytes(n, 3)
+		if err != nil {
+			t.Fatalf("EncodeToBytes(%d, 3): %v", n, err)
+		}
+		if len(b) != 3 {
+			t.Errorf("EncodeToBytes(%d, 3) = %v, want 3 bytes", n, b)
+		}
+		if decoded, err := i.DecodeFromBytes(b); err != nil || decoded != n {
+			t.Errorf("DecodeFromBytes(%v) = %d, %v, want %d", b, decoded, err, n)
+		}
+	}
+	if b, err := i.EncodeToBytes(largest+1, 3); err == nil {
+		t.Errorf("EncodeToBytes(%d, 3) = %v, want an error", largest+1, b)
+	}
+	if b, err := i.EncodeToBytes(largest+1, 4); err != nil {
+		t.Errorf("EncodeToBytes(%d, 4): %v", largest+1, err)
+	} else if decoded, err := i.DecodeFromBytes(b); err != nil || decoded != largest+1 {
+		t.Errorf("DecodeFromBytes(%v) = %d, %v, want %d", b, decoded, err, largest+1)
+	}
+}
+
+func TestEncodeToBytesRoundTrip(t *testing.T) {
+	i := testEncoder()
+	for _, n := range testValues() {
+		b, err := i.EncodeToBytes(n, 14)
+		if err != nil {
+			t.Fatalf("EncodeToBytes(%d, 14): %v", n, err)
+		}
+		if decoded, err := i.DecodeFromBytes(b); err != nil || decoded != n {
+			t.Errorf("DecodeFromBytes(%v) = %d, %v, want %d", b, decoded, err, n)
+		}
+	}
+	if _, err := i.DecodeFromBytes([]byte{0, byte(len(i.Alphabet))}); err == nil {
+		t.Error("DecodeFromBytes accepted a digit outside the alphabet")
+	}
+}
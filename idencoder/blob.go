@@ -0,0 +1,98 @@
+package idencoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+)
+
+// ErrMalformedBlob is returned when a decoded blob's length prefix doesn't match its contents
+var ErrMalformedBlob = &IdEncoderError{Message: "Malformed blob"}
+
+// EncodeBlob converts a byte slice of any length to a string. The bytes are prefixed
+// with their length before conversion to the alphabet, so DecodeBlob recovers them
+// exactly, including any leading zero bytes. The bytes are read as a big-endian
+// number on every platform. Blobs are not scrambled, and the checksum is computed
+// from their final 8 bytes. Returns an error if the encoded value exceeds MaxLength.
+func (i *IdEncoder) EncodeBlob(b []byte, minLength uint64) (string, error) {
+	payload := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(b))
+	payload = append(payload[:binary.PutUvarint(payload, uint64(len(b))+1)], b...)
+	data := i.appendBigBase(nil, new(big.Int).SetBytes(payload), minLength)
+	if uint64(len(data)) > i.maxLength() {
+		return "", &IdEncoderError{
+			Message: "Encoded value exceeds maximum length",
+		}
+	}
+	raw := string(i.checksum(blobChecksumValue(payload), data)) + string(data)
+	if i.formatted() {
+		return i.format(raw), nil
+	}
+	return raw, nil
+}
+
+// DecodeBlob converts a string produced by EncodeBlob back to the original bytes.
+// Like Decode, a checksum mismatch returns the bytes along with ErrChecksumMismatch.
+func (i *IdEncoder) DecodeBlob(s string) ([]byte, error) {
+	raw, err := i.parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 2 {
+		return nil, ErrTooShort
+	}
+	if uint64(len(raw)) > 1+i.maxLength() {
+		return nil, ErrTooLong
+	}
+	radix := big.NewInt(int64(len(i.Alphabet)))
+	value, digit := new(big.Int), new(big.Int)
+	for idx := 0; idx < len(raw); idx++ {
+		d := bytes.IndexByte(i.Alphabet, raw[idx])
+		if d < 0 {
+			return nil, &InvalidCharacterError{Index: i.originalIndex(s, idx), Char: raw[idx]}
+		}
+		if idx > 0 {
+			value.Mul(value, radix).Add(value, digit.SetInt64(int64(d)))
+		}
+	}
+	payload := value.Bytes()
+	length, k := binary.Uvarint(payload)
+	if k <= 0 || length == 0 || uint64(len(payload)-k) != length-1 {
+		return nil, ErrMalformedBlob
+	}
+	b := payload[k:]
+	if i.checksum(blobChecksumValue(payload), []byte(raw[1:])) != raw[0] {
+		return b, ErrChecksumMismatch
+	}
+	return b, nil
+}
+
+// appendBigBase appends the digits of n in the base of the alphabet to dst, left
+// padded with the first character of the alphabet to at least minLength digits
+func (i *IdEncoder) appendBigBase(dst []byte, n *big.Int, minLength uint64) []byte {
+	start := len(dst)
+	radix := big.NewInt(int64(len(i.Alphabet)))
+	x, digit := new(big.Int).Set(n), new(big.Int)
+	for x.Sign() > 0 {
+		x.QuoRem(x, radix, digit)
+		dst = append(dst, i.Alphabet[digit.Int64()])
+	}
+	for uint64(len(dst)-start) < minLength || len(dst) == start {
+		dst = append(dst, i.Alphabet[0])
+	}
+	for l, r := start, len(dst)-1; l < r; l, r = l+1, r-1 {
+		dst[l], dst[r] = dst[r], dst[l]
+	}
+	return dst
+}
+
+// blobChecksumValue returns the integer formed by the final 8 bytes of a blob payload
+func blobChecksumValue(payload []byte) uint64 {
+	var n uint64
+	if len(payload) > 8 {
+		payload = payload[len(payload)-8:]
+	}
+	for _, c := range payload {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
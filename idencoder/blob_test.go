@@ -0,0 +1,56 @@
+package idencoder
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestBlobRoundTrip(t *testing.T) {
+	i := testEncoder()
+	for _, b := range [][]byte{
+		{},
+		{0},
+		{7},
+		{0xff},
+		{0, 0, 1},
+		{0, 0, 0, 0},
+		[]byte("hello, world"),
+		bytes.Repeat([]byte{0xa5}, 20),
+	} {
+		for _, minLength := range []uint64{0, 10} {
+			code, err := i.EncodeBlob(b, minLength)
+			if err != nil {
+				t.Fatalf("EncodeBlob(%v, %d): %v", b, minLength, err)
+			}
+			if uint64(len(code)) < 1+minLength {
+				t.Errorf("EncodeBlob(%v, %d) = %q, want at least %d characters", b, minLength, code, 1+minLength)
+			}
+			decoded, err := i.DecodeBlob(code)
+			if err != nil || !bytes.Equal(decoded, b) {
+				t.Errorf("DecodeBlob(%q) = %v, %v, want %v", code, decoded, err, b)
+			}
+		}
+	}
+	// leading zero bytes are part of the blob
+	a, _ := i.EncodeBlob([]byte{1}, 0)
+	b, _ := i.EncodeBlob([]byte{0, 1}, 0)
+	if a == b {
+		t.Errorf("EncodeBlob of {1} and {0, 1} are both %q", a)
+	}
+}
+
+func TestBlobTampering(t *testing.T) {
+	i := testEncoder()
+	code, err := i.EncodeBlob([]byte("hello"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := string(i.Alphabet[(i.digit(code[0])+1)%int(i.Checksum)]) + code[1:]
+	if decoded, err := i.DecodeBlob(bad); !errors.Is(err, ErrChecksumMismatch) || string(decoded) != "hello" {
+		t.Errorf("DecodeBlob(%q) = %q, %v, want \"hello\" and ErrChecksumMismatch", bad, decoded, err)
+	}
+	if _, err := i.DecodeBlob(code[:1]); !errors.Is(err, ErrTooShort) {
+		t.Errorf("DecodeBlob(%q) error %v, want ErrTooShort", code[:1], err)
+	}
+}
@@ -0,0 +1,112 @@
+package idencoder
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CachingEncoder wraps an IdEncoder with bounded, least-recently-used caches of
+// encode and decode results. It is safe for concurrent use.
+type CachingEncoder struct {
+	*IdEncoder
+
+	mu      sync.Mutex
+	encodes *lru
+	decodes *lru
+}
+
+type encodeKey struct {
+	n, minLength uint64
+}
+
+// NewCachingEncoder returns a CachingEncoder remembering up to size encode and size decode results
+func NewCachingEncoder(enc *IdEncoder, size int) *CachingEncoder {
+	return &CachingEncoder{
+		IdEncoder: enc,
+		encodes:   newLRU(size),
+		decodes:   newLRU(size),
+	}
+}
+
+// Encode converts an integer to a unique string like IdEncoder.Encode, using a cached result if possible
+func (c *CachingEncoder) Encode(n, minLength uint64) (encoded string, err error) {
+	key := encodeKey{n, minLength}
+	c.mu.Lock()
+	cached, ok := c.encodes.get(key)
+	c.mu.Unlock()
+	if ok {
+		return cached.(string), nil
+	}
+	encoded, err = c.IdEncoder.Encode(n, minLength)
+	if err != nil {
+		return encoded, err
+	}
+	c.mu.Lock()
+	c.encodes.add(key, encoded)
+	c.mu.Unlock()
+	return encoded, nil
+}
+
+// Decode converts a string to an integer like IdEncoder.Decode, using a cached result if possible.
+// Only successful decodes are cached.
+func (c *CachingEncoder) Decode(s string) (decoded uint64, err error) {
+	c.mu.Lock()
+	cached, ok := c.decodes.get(s)
+	c.mu.Unlock()
+	if ok {
+		return cached.(uint64), nil
+	}
+	decoded, err = c.IdEncoder.Decode(s)
+	if err != nil {
+		return decoded, err
+	}
+	c.mu.Lock()
+	c.decodes.add(s, decoded)
+	c.mu.Unlock()
+	return decoded, nil
+}
+
+// lru is a fixed size least-recently-used cache. It is not safe for concurrent use.
+type lru struct {
+	size    int
+	order   *list.List
+	entries map[interface{}]*list.Element
+}
+
+type lruEntry struct {
+	key, value interface{}
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[interface{}]*list.Element),
+	}
+}
+
+func (l *lru) get(key interface{}) (interface{}, bool) {
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (l *lru) add(key, value interface{}) {
+	if l.size <= 0 {
+		return
+	}
+	if elem, ok := l.entries[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key, value})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
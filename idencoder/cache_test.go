@@ -0,0 +1,84 @@
+package idencoder
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// skewedValues returns values drawn from a Zipf distribution, so a few hot values
+// make up most of them
+func skewedValues(count int) []uint64 {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.2, 1, 100000)
+	values := make([]uint64, count)
+	for idx := range values {
+		values[idx] = z.Uint64()
+	}
+	return values
+}
+
+func TestCachingEncoderMatchesUncached(t *testing.T) {
+	i := keyedEncoder("secret")
+	c := NewCachingEncoder(i, 64)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, n := range skewedValues(5000) {
+				want, _ := i.Encode(n, MinLength)
+				got, err := c.Encode(n, MinLength)
+				if err != nil || got != want {
+					t.Errorf("cached Encode(%d) = %q, %v, want %q", n, got, err, want)
+					return
+				}
+				decoded, err := c.Decode(got)
+				if err != nil || decoded != n {
+					t.Errorf("cached Decode(%q) = %d, %v, want %d", got, decoded, err, n)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// failed decodes aren't cached
+	if _, err := c.Decode("!!!!!!"); err == nil {
+		t.Error("cached Decode accepted an invalid code")
+	}
+	if _, err := c.Decode("!!!!!!"); err == nil {
+		t.Error("cached Decode accepted an invalid code the second time")
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	l := newLRU(2)
+	l.add("a", 1)
+	l.add("b", 2)
+	l.get("a")
+	l.add("c", 3)
+	if _, ok := l.get("b"); ok {
+		t.Error("b wasn't evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.get(key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+}
+
+func BenchmarkCachingEncoderSkewed(b *testing.B) {
+	i := keyedEncoder("secret")
+	values := skewedValues(10000)
+	b.Run("Uncached", func(b *testing.B) {
+		for j := 0; j < b.N; j++ {
+			encodeSink, _ = i.Encode(values[j%len(values)], MinLength)
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		c := NewCachingEncoder(i, 1024)
+		for j := 0; j < b.N; j++ {
+			encodeSink, _ = c.Encode(values[j%len(values)], MinLength)
+		}
+	})
+}
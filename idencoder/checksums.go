@@ -0,0 +1,77 @@
+package idencoder
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ChecksumFunc computes the checksum character for the value n, whose encoded data
+// characters are data, returning its index in the encoder's alphabet. Results
+// beyond the alphabet wrap around.
+type ChecksumFunc func(i *IdEncoder, n uint64, data []byte) uint64
+
+// ErrUnknownChecksum is returned for a ChecksumName that isn't registered
+var ErrUnknownChecksum = &IdEncoderError{Message: "Unknown checksum"}
+
+// builtinChecksums names the checksum modes, which are always registered
+var builtinChecksums = map[string]ChecksumMode{
+	"value":     CheckValue,
+	"output":    CheckOutput,
+	"padded":    CheckPadded,
+	"scrambled": CheckScrambled,
+}
+
+var (
+	checksumsMu sync.RWMutex
+	checksums   = make(map[string]ChecksumFunc)
+)
+
+// RegisterChecksum makes a checksum algorithm available by name, so encoders can
+// select it with ChecksumName. The checksum modes are registered as "value",
+// "output", "padded" and "scrambled". Returns an error if name is empty or already
+// registered.
+func RegisterChecksum(name string, fn ChecksumFunc) error {
+	if name == "" || fn == nil {
+		return &IdEncoderError{Message: "Checksum must have a name and a function"}
+	}
+	checksumsMu.Lock()
+	defer checksumsMu.Unlock()
+	if _, ok := builtinChecksums[name]; ok || checksums[name] != nil {
+		return &IdEncoderError{Message: fmt.Sprintf("Checksum %q is already registered", name)}
+	}
+	checksums[name] = fn
+	return nil
+}
+
+// lookupChecksum returns the checksum registered as name
+func lookupChecksum(name string) (mode ChecksumMode, fn ChecksumFunc, ok bool) {
+	if mode, ok := builtinChecksums[name]; ok {
+		return mode, nil, true
+	}
+	checksumsMu.RLock()
+	fn = checksums[name]
+	checksumsMu.RUnlock()
+	return 0, fn, fn != nil
+}
+
+// checksumAlgorithm returns the checksum mode in effect, or the registered function
+// selected by ChecksumName. An unregistered name falls back to ChecksumMode.
+func (i *IdEncoder) checksumAlgorithm() (ChecksumMode, ChecksumFunc) {
+	if i.checksumFunc != nil {
+		return 0, i.checksumFunc
+	}
+	if i.ChecksumName == "" {
+		return i.ChecksumMode, nil
+	}
+	if mode, fn, ok := lookupChecksum(i.ChecksumName); ok {
+		return mode, fn
+	}
+	return i.ChecksumMode, nil
+}
+
+// WithChecksumName selects a checksum registered with RegisterChecksum
+func WithChecksumName(name string) Option {
+	return func(i *IdEncoder) {
+		i.ChecksumName = name
+	}
+}
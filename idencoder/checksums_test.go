@@ -0,0 +1,293 @@
+package idencoder
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// transpositions calls f with each code for a sample of values and a copy with two
+// adjacent, differing data characters swapped
+func transpositions(i *IdEncoder, f func(code, swapped string, a, b byte)) {
+	for n := uint64(1); n < 20000; n += 7 {
+		code, _ := i.Encode(n, 6)
+		for k := 1; k+1 < len(code); k++ {
+			if code[k] == code[k+1] {
+				continue
+			}
+			swapped := []byte(code)
+			swapped[k], swapped[k+1] = swapped[k+1], swapped[k]
+			f(code, string(swapped), code[k], code[k+1])
+		}
+	}
+}
+
+func TestCheckOutputCatchesTranspositions(t *testing.T) {
+	for _, alphabet := range []string{DefaultAlphabet, "0123456789abcdefghijklmnopqrstuv"} {
+		i := testEncoder()
+		i.Alphabet = Alphabet(alphabet)
+		i.ChecksumMode = CheckOutput
+		first, last := i.Alphabet[0], i.Alphabet[len(i.Alphabet)-1]
+		transpositions(i, func(code, swapped string, a, b byte) {
+			_, err := i.Decode(swapped)
+			// like any Luhn mod N check, swapping the digits 0 and N-1 goes unnoticed
+			if a == first && b == last || a == last && b == first {
+				return
+			}
+			if err == nil {
+				t.Errorf("%d character alphabet: transposing %q to %q isn't detected", len(alphabet), code, swapped)
+			}
+		})
+	}
+}
+
+func TestCheckValueMissesSomeTranspositions(t *testing.T) {
+	value := testEncoder()
+	output := testEncoder()
+	output.ChecksumMode = CheckOutput
+	missed := func(i *IdEncoder) (n int) {
+		transpositions(i, func(code, swapped string, a, b byte) {
+			if _, err := i.Decode(swapped); err == nil {
+				n++
+			}
+		})
+		return n
+	}
+	if v, o := missed(value), missed(output); o >= v {
+		t.Errorf("CheckOutput misses %d transpositions, CheckValue %d; want fewer", o, v)
+	}
+}
+
+func TestCheckPaddedRejectsChangedPadding(t *testing.T) {
+	padded := testEncoder()
+	padded.ChecksumMode = CheckPadded
+	plain := testEncoder()
+	for _, n := range []uint64{0, 1, 1000, 123456789} {
+		code, err := padded.Encode(n, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := padded.Decode(code); err != nil || decoded != n {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+		}
+		extra := code[:1] + string(padded.padChar()) + code[1:]
+		if _, err := padded.Decode(extra); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Decode(%q) with an added pad character error %v, want ErrChecksumMismatch", extra, err)
+		}
+		if code[1] == padded.padChar() {
+			fewer := code[:1] + code[2:]
+			if _, err := padded.Decode(fewer); !errors.Is(err, ErrChecksumMismatch) {
+				t.Errorf("Decode(%q) with a removed pad character error %v, want ErrChecksumMismatch", fewer, err)
+			}
+		}
+		// CheckValue doesn't notice extra padding
+		code, _ = plain.Encode(n, 8)
+		extra = code[:1] + string(plain.padChar()) + code[1:]
+		if decoded, err := plain.Decode(extra); err != nil || decoded != n {
+			t.Errorf("CheckValue Decode(%q) = %d, %v, want %d", extra, decoded, err, n)
+		}
+	}
+}
+
+// The "test-length" checksum is the data length, so the checksum character is
+// predictable. The registry is global, so it is registered once however many times
+// the test runs.
+var (
+	lengthChecksumOnce  sync.Once
+	lengthChecksumCalls int
+	lengthChecksumErr   error
+)
+
+func TestRegisterChecksum(t *testing.T) {
+	lengthChecksumOnce.Do(func() {
+		lengthChecksumErr = RegisterChecksum("test-length", func(i *IdEncoder, n uint64, data []byte) uint64 {
+			lengthChecksumCalls++
+			return uint64(len(data))
+		})
+	})
+	if lengthChecksumErr != nil {
+		t.Fatal(lengthChecksumErr)
+	}
+	lengthChecksumCalls = 0
+	i, err := New(WithChecksumName("test-length"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := i.Encode(1000, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lengthChecksumCalls == 0 || code[0] != i.Alphabet[6] {
+		t.Errorf("Encode(1000, 6) = %q after %d calls, want the checksum %q", code, lengthChecksumCalls, i.Alphabet[6])
+	}
+	if decoded, err := i.Decode(code); err != nil || decoded != 1000 {
+		t.Errorf("Decode(%q) = %d, %v, want 1000", code, decoded, err)
+	}
+	tampered := string(i.Alphabet[7]) + code[1:]
+	if _, err := i.Decode(tampered); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Decode(%q) error %v, want ErrChecksumMismatch", tampered, err)
+	}
+
+	if _, err := New(WithChecksumName("test-unregistered")); !errors.Is(err, ErrUnknownChecksum) {
+		t.Errorf("New with an unknown checksum name error %v, want ErrUnknownChecksum", err)
+	}
+	for _, name := range []string{"", "test-length", "output"} {
+		if err := RegisterChecksum(name, func(*IdEncoder, uint64, []byte) uint64 { return 0 }); err == nil {
+			t.Errorf("RegisterChecksum(%q) succeeded", name)
+		}
+	}
+
+	// built-in modes are selectable by name
+	named, err := New(WithChecksumName("output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mode := testEncoder()
+	mode.ChecksumMode = CheckOutput
+	for _, n := range testValues() {
+		a, _ := named.Encode(n, MinLength)
+		b, _ := mode.Encode(n, MinLength)
+		if a != b {
+			t.Errorf("Encode(%d) with ChecksumName \"output\" = %q, want %q", n, a, b)
+		}
+	}
+}
+
+func TestChecksumPlacementWithPadding(t *testing.T) {
+	for _, padChar := range []byte{0, '.'} {
+		leading := testEncoder()
+		leading.BlockSize = 0
+		leading.PadChar = padChar
+		trailing := testEncoder()
+		trailing.BlockSize = 0
+		trailing.PadChar = padChar
+		trailing.ChecksumPlacement = ChecksumTrailing
+		pad := leading.padChar()
+		// 5 is a single data character, padded with 7 more
+		data := []byte{leading.Alphabet[5]}
+		checksum := leading.checksum(5, data)
+
+		code, err := leading.Encode(5, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := string(checksum) + strings.Repeat(string(pad), 7) + string(data); code != want {
+			t.Errorf("leading Encode(5, 8) = %q, want %q: checksum, padding, data", code, want)
+		}
+		code, err = trailing.Encode(5, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.Repeat(string(pad), 7) + string(data) + string(checksum); code != want {
+			t.Errorf("trailing Encode(5, 8) = %q, want %q: padding, data, checksum", code, want)
+		}
+
+		for _, i := range []*IdEncoder{leading, trailing} {
+			code, _ := i.Encode(5, 8)
+			if decoded, err := i.Decode(code); err != nil || decoded != 5 {
+				t.Errorf("Decode(%q) = %d, %v, want 5", code, decoded, err)
+			}
+			// the checksum is the first (or last) character, not the one after the padding
+			at := 0
+			if i.ChecksumPlacement == ChecksumTrailing {
+				at = len(code) - 1
+			}
+			wrong := i.Alphabet[(i.digit(code[at])+1)%int(i.Checksum)]
+			tampered := code[:at] + string(wrong) + code[at+1:]
+			if _, err := i.Decode(tampered); !errors.Is(err, ErrChecksumMismatch) {
+				t.Errorf("Decode(%q) error %v, want ErrChecksumMismatch", tampered, err)
+			}
+		}
+	}
+}
+
+func TestChecksumBeyondAlphabet(t *testing.T) {
+	alphabet := DefaultAlphabet[:25]
+	if _, err := New(WithAlphabet(alphabet), WithChecksum(DefaultChecksum)); err == nil {
+		t.Error("New accepted Checksum 29 with a 25 character alphabet")
+	}
+	// an encoder built directly isn't validated, but must not panic either
+	i := testEncoder()
+	i.Alphabet = Alphabet(alphabet)
+	for _, mode := range []ChecksumMode{CheckValue, CheckPadded, CheckScrambled} {
+		i.ChecksumMode = mode
+		for n := uint64(0); n < 100; n++ {
+			code, err := i.Encode(n, MinLength)
+			if err != nil {
+				t.Fatalf("mode %d: Encode(%d): %v", mode, n, err)
+			}
+			if strings.IndexByte(alphabet, code[0]) < 0 {
+				t.Errorf("mode %d: Encode(%d) = %q has a checksum outside the alphabet", mode, n, code)
+			}
+			if decoded, err := i.Decode(code); err != nil || decoded != n {
+				t.Errorf("mode %d: Decode(%q) = %d, %v, want %d", mode, code, decoded, err, n)
+			}
+		}
+	}
+}
+
+func TestDecodeCorrecting(t *testing.T) {
+	i := testEncoder()
+	const code = "gr8r7s9" // 123456789, see TestGoldenCodes
+	if value, corrected, err := i.DecodeCorrecting(code); err != nil || value != 123456789 || corrected {
+		t.Errorf("DecodeCorrecting(%q) = %d, %t, %v, want 123456789 uncorrected", code, value, corrected, err)
+	}
+	// every adjacent transposition of this code, checksum included, fails the
+	// checksum and has a single correction
+	for k := 0; k+1 < len(code); k++ {
+		b := []byte(code)
+		b[k], b[k+1] = b[k+1], b[k]
+		swapped := string(b)
+		if _, err := i.Decode(swapped); !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("Decode(%q) error %v, want ErrChecksumMismatch", swapped, err)
+		}
+		if value, corrected, err := i.DecodeCorrecting(swapped); err != nil || value != 123456789 || !corrected {
+			t.Errorf("DecodeCorrecting(%q) = %d, %t, %v, want 123456789 corrected", swapped, value, corrected, err)
+		}
+	}
+	// a substitution isn't a transposition, although a swap happens to validate
+	// a few of them, like "gr8r7sr", as the documentation warns
+	if _, corrected, err := i.DecodeCorrecting("gr8r7sf"); corrected || !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("DecodeCorrecting(\"gr8r7sf\") corrected %t, error %v, want ErrChecksumMismatch", corrected, err)
+	}
+	if _, corrected, err := i.DecodeCorrecting("gr8!7s9"); corrected || !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("DecodeCorrecting(\"gr8!7s9\") corrected %t, error %v, want ErrInvalidCharacter", corrected, err)
+	}
+}
+
+func TestSubstitutionsDetected(t *testing.T) {
+	for _, mode := range []ChecksumMode{CheckValue, CheckScrambled} {
+		i := testEncoder()
+		i.ChecksumMode = mode
+		total, missed := 0, 0
+		for n := uint64(1); n < 5000; n += 7 {
+			code, err := i.Encode(n, 6)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k := 1; k < len(code); k++ {
+				for d := 0; d < len(i.Alphabet); d++ {
+					if i.Alphabet[d] == code[k] {
+						continue
+					}
+					b := []byte(code)
+					b[k] = i.Alphabet[d]
+					total++
+					if _, err := i.Decode(string(b)); err == nil {
+						missed++
+						// the scrambled value changes by a multiple of a power of the
+						// radix, so only a digit change that is a multiple of Checksum
+						// goes unnoticed
+						if delta := d - strings.IndexByte(DefaultAlphabet, code[k]); mode == CheckScrambled && delta%int(i.Checksum) != 0 {
+							t.Errorf("CheckScrambled: substituting %q to %q isn't detected", code, b)
+						}
+					}
+				}
+			}
+		}
+		if missed*int(i.Checksum) > 2*total {
+			t.Errorf("mode %d misses %d of %d substitutions, want about 1 in %d at most", mode, missed, total, i.Checksum)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package idencoder
+
+// FindCollision reports whether two different integers produce the same encoded value
+// with the encoder's MinLength
+func (i *IdEncoder) FindCollision(a, b uint64) bool {
+	if a == b {
+		return false
+	}
+	encodedA, err := i.Encode(a, i.minLength())
+	if err != nil {
+		return false
+	}
+	encodedB, err := i.Encode(b, i.minLength())
+	if err != nil {
+		return false
+	}
+	return encodedA == encodedB
+}
+
+// FirstCollision scans the range [0, max] and returns the first pair of integers that
+// produce the same encoded value with the encoder's MinLength. found is false if
+// every value in the range is unique.
+func (i *IdEncoder) FirstCollision(max uint64) (a, b uint64, found bool, err error) {
+	seen := make(map[string]uint64)
+	for n := uint64(0); ; n++ {
+		encoded, err := i.Encode(n, i.minLength())
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if prev, ok := seen[encoded]; ok {
+			return prev, n, true, nil
+		}
+		seen[encoded] = n
+		if n == max {
+			break
+		}
+	}
+	return 0, 0, false, nil
+}
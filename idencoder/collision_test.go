@@ -0,0 +1,49 @@
+package idencoder
+
+import "testing"
+
+// strictLengthEncoder returns an encoder that only accepts codes of its MinLength
+func strictLengthEncoder() *IdEncoder {
+	i := testEncoder()
+	i.MinLength = 8
+	i.StrictLength = true
+	return i
+}
+
+func TestCollisionChecksUseMinLength(t *testing.T) {
+	i := strictLengthEncoder()
+	if err := i.VerifyNoCollisions(1000); err != nil {
+		t.Errorf("VerifyNoCollisions: %v", err)
+	}
+	if _, _, found, err := i.FirstCollision(1000); found || err != nil {
+		t.Errorf("FirstCollision found %v, err %v", found, err)
+	}
+	if i.FindCollision(1, 2) {
+		t.Error("FindCollision(1, 2) = true")
+	}
+}
+
+func TestFirstCollisionFindsBadConfig(t *testing.T) {
+	// 'f' is both digit 15 and digit 16, so 15 and 16 encode alike
+	i := &IdEncoder{
+		Alphabet: Alphabet("0123456789abcdeff"),
+		Checksum: 17,
+	}
+	a, b, found, err := i.FirstCollision(1000)
+	if err != nil || !found || a != 15 || b != 16 {
+		t.Fatalf("FirstCollision(1000) = %d, %d, %t, %v, want 15, 16", a, b, found, err)
+	}
+	if !i.FindCollision(a, b) {
+		t.Errorf("FindCollision(%d, %d) = false", a, b)
+	}
+	if i.FindCollision(a, a+2) {
+		t.Errorf("FindCollision(%d, %d) = true", a, a+2)
+	}
+	if err := i.VerifyNoCollisions(1000); err == nil {
+		t.Error("VerifyNoCollisions(1000) = nil")
+	}
+	// below the first collision, the range is clean
+	if _, _, found, err := i.FirstCollision(15); found || err != nil {
+		t.Errorf("FirstCollision(15) found %t, err %v", found, err)
+	}
+}
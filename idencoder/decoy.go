@@ -0,0 +1,36 @@
+package idencoder
+
+// FNV-1a parameters for hashing an encoded value into its decoys
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// appendDecoys appends DecoyPrefixLen characters derived from the raw encoded value.
+// The hash is salted, and keyed when a scramble key is set, so decoys can't be
+// recomputed without the encoder's configuration.
+func (i *IdEncoder) appendDecoys(dst []byte, raw string) []byte {
+	h := uint64(fnvOffset)
+	for idx := 0; idx < len(raw); idx++ {
+		h ^= uint64(raw[idx])
+		h *= fnvPrime
+	}
+	h ^= i.Salt
+	if i.scrambleKey != nil {
+		h = i.permute(h)
+	}
+	base := uint64(len(i.Alphabet))
+	for k := 0; k < i.DecoyPrefixLen; k++ {
+		h = splitmix64(h)
+		dst = append(dst, i.Alphabet[h%base])
+	}
+	return dst
+}
+
+// splitmix64 advances and mixes a 64-bit state
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
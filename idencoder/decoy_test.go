@@ -0,0 +1,57 @@
+package idencoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecoysRoundTrip(t *testing.T) {
+	i := testEncoder()
+	i.Prefix = "id_"
+	i.DecoyPrefixLen = 4
+	plain := testEncoder()
+	for _, n := range testValues() {
+		code, err := i.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := plain.Encode(n, MinLength)
+		if code != i.Prefix+code[len(i.Prefix):len(i.Prefix)+4]+raw {
+			t.Errorf("Encode(%d) = %q, want %q, 4 decoys and %q", n, code, i.Prefix, raw)
+		}
+		if decoded, err := i.Decode(code); err != nil || decoded != n {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+		}
+	}
+}
+
+func TestDecoysDetectTampering(t *testing.T) {
+	i := testEncoder()
+	i.DecoyPrefixLen = 3
+	for _, n := range []uint64{0, 1, 1000, 123456789} {
+		code, err := i.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for idx := 0; idx < len(code); idx++ {
+			// replace each character with the next one in the alphabet
+			c := i.Alphabet[(i.digit(code[idx])+1)%len(i.Alphabet)]
+			tampered := code[:idx] + string(c) + code[idx+1:]
+			_, err := i.Decode(tampered)
+			if idx < i.DecoyPrefixLen && !errors.Is(err, ErrDecoyMismatch) {
+				t.Errorf("Decode(%q) with a changed decoy error %v, want ErrDecoyMismatch", tampered, err)
+			}
+			if err == nil {
+				t.Errorf("Decode(%q) accepted a tampered code", tampered)
+			}
+		}
+	}
+	// the same data with another value's decoys is rejected
+	a, _ := i.Encode(1, MinLength)
+	b, _ := i.Encode(2, MinLength)
+	if swapped := b[:3] + a[3:]; swapped != a {
+		if _, err := i.Decode(swapped); !errors.Is(err, ErrDecoyMismatch) {
+			t.Errorf("Decode(%q) error %v, want ErrDecoyMismatch", swapped, err)
+		}
+	}
+}
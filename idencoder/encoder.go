@@ -0,0 +1,15 @@
+package idencoder
+
+// Encoder converts integers to strings and back. IdEncoder, CachingEncoder and
+// RuneEncoder all implement it, so code can depend on Encoder and substitute any
+// of them, or a fake in tests, while configuration stays on the concrete types.
+type Encoder interface {
+	Encode(n, minLength uint64) (string, error)
+	Decode(s string) (uint64, error)
+}
+
+var (
+	_ Encoder = (*IdEncoder)(nil)
+	_ Encoder = (*CachingEncoder)(nil)
+	_ Encoder = (*RuneEncoder)(nil)
+)
@@ -0,0 +1,45 @@
+package idencoder
+
+import (
+	"strconv"
+	"testing"
+)
+
+// decimalEncoder is a fake Encoder that writes values in decimal
+type decimalEncoder struct {
+	encoded int
+}
+
+func (d *decimalEncoder) Encode(n, minLength uint64) (string, error) {
+	d.encoded++
+	return strconv.FormatUint(n, 10), nil
+}
+
+func (d *decimalEncoder) Decode(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// shortLink stands for code that depends on an Encoder rather than a concrete type
+func shortLink(e Encoder, id uint64) (string, error) {
+	code, err := e.Encode(id, MinLength)
+	if err != nil {
+		return "", err
+	}
+	return "/s/" + code, nil
+}
+
+func TestEncoderSubstitution(t *testing.T) {
+	fake := &decimalEncoder{}
+	if link, err := shortLink(fake, 42); err != nil || link != "/s/42" || fake.encoded != 1 {
+		t.Errorf("shortLink with a fake = %q, %v after %d calls, want \"/s/42\" after 1", link, err, fake.encoded)
+	}
+	for _, e := range []Encoder{testEncoder(), NewCachingEncoder(testEncoder(), 8), fake} {
+		link, err := shortLink(e, 123456789)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := e.Decode(link[len("/s/"):]); err != nil || decoded != 123456789 {
+			t.Errorf("%T: Decode(%q) = %d, %v, want 123456789", e, link, decoded, err)
+		}
+	}
+}
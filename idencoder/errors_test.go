@@ -0,0 +1,77 @@
+package idencoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInvalidCharacterIndex(t *testing.T) {
+	grouped := testEncoder()
+	grouped.Prefix = "id_"
+	grouped.GroupSize = 3
+	trailing := testEncoder()
+	trailing.ChecksumPlacement = ChecksumTrailing
+	for _, i := range []*IdEncoder{testEncoder(), grouped, trailing} {
+		code, err := i.Encode(123456789, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for idx := len(i.Prefix); idx < len(code); idx++ {
+			if code[idx] == i.separator() && i.GroupSize > 0 {
+				continue
+			}
+			crafted := code[:idx] + "!" + code[idx+1:]
+			_, err := i.Decode(crafted)
+			if !errors.Is(err, ErrInvalidCharacter) {
+				t.Errorf("Decode(%q) error %v isn't ErrInvalidCharacter", crafted, err)
+				continue
+			}
+			var invalid *InvalidCharacterError
+			if !errors.As(err, &invalid) {
+				t.Errorf("Decode(%q) error %v isn't an InvalidCharacterError", crafted, err)
+				continue
+			}
+			if invalid.Index != idx || invalid.Char != '!' || crafted[invalid.Index] != '!' {
+				t.Errorf("Decode(%q) reports %q at index %d, want '!' at %d", crafted, invalid.Char, invalid.Index, idx)
+			}
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	i := testEncoder()
+	code, err := i.Encode(123456789, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// replace the checksum with a different alphabet character
+	tampered := string(i.Alphabet[(i.digit(code[0])+1)%int(i.Checksum)]) + code[1:]
+	for _, tc := range []struct {
+		s    string
+		want error
+	}{
+		{tampered, ErrChecksumMismatch},
+		{"", ErrTooShort},
+		{code + strings.Repeat(code[1:], 10), ErrTooLong},
+		{code[:3] + "!" + code[4:], ErrInvalidCharacter},
+	} {
+		_, err := i.Decode(tc.s)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("Decode(%q) error %v, want %v", tc.s, err, tc.want)
+		}
+		var invalid *InvalidCharacterError
+		if errors.As(err, &invalid) && (tc.want != ErrInvalidCharacter || invalid.Index != 3) {
+			t.Errorf("Decode(%q) error %v reports an invalid character at index %d", tc.s, err, invalid.Index)
+		} else if invalid == nil && tc.want == ErrInvalidCharacter {
+			t.Errorf("Decode(%q) error %v isn't an InvalidCharacterError", tc.s, err)
+		}
+	}
+	large, err := i.Encode(1<<40, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.DecodeUint32(large); !errors.Is(err, ErrOverflow) {
+		t.Errorf("DecodeUint32(%q) error %v, want ErrOverflow", large, err)
+	}
+}
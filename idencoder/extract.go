@@ -0,0 +1,93 @@
+package idencoder
+
+import "strings"
+
+// ExtractAll finds encoded values in free text, such as URLs or log lines, and returns
+// their decoded values in the order they appear. Every maximal run of characters
+// that can appear in a code (the alphabet, and the prefix, separator, pad character,
+// zero symbol and other-case letters the encoder accepts) is a candidate. With a
+// Prefix, a run is split where the prefix starts. Candidates of a plausible length
+// are decoded, and those that fail to decode are discarded. This is a heuristic:
+// the checksum filters out most, but not all, runs that merely look like encoded
+// values.
+func (i *IdEncoder) ExtractAll(text string) []uint64 {
+	accepted := i.codeChars()
+	var found []uint64
+	start := -1
+	for idx := 0; idx <= len(text); idx++ {
+		if idx < len(text) && accepted[text[idx]] {
+			if start < 0 {
+				start = idx
+			}
+			continue
+		}
+		if start >= 0 {
+			found = i.extractRun(found, text[start:idx])
+		}
+		start = -1
+	}
+	return found
+}
+
+// codeChars returns the set of characters that Decode accepts somewhere in a code
+func (i *IdEncoder) codeChars() *[256]bool {
+	var set [256]bool
+	for _, c := range i.Alphabet {
+		set[c] = true
+		if i.FoldCase {
+			set[swapCase(c)] = true
+		}
+	}
+	for idx := 0; idx < len(i.Prefix); idx++ {
+		set[i.Prefix[idx]] = true
+	}
+	if i.GroupSize > 0 {
+		set[i.separator()] = true
+	}
+	if i.PadChar != 0 {
+		set[i.PadChar] = true
+	}
+	if i.ZeroSymbol != 0 {
+		set[i.ZeroSymbol] = true
+	}
+	return &set
+}
+
+// extractRun appends the values of the codes found in run, a maximal run of code
+// characters, to found. With a Prefix, each candidate starts at an occurrence of
+// the prefix and ends before the next one.
+func (i *IdEncoder) extractRun(found []uint64, run string) []uint64 {
+	if i.Prefix == "" {
+		return i.extractCandidate(found, run)
+	}
+	for {
+		start := strings.Index(run, i.Prefix)
+		if start < 0 {
+			return found
+		}
+		run = run[start:]
+		next := strings.Index(run[len(i.Prefix):], i.Prefix)
+		if next < 0 {
+			return i.extractCandidate(found, run)
+		}
+		found = i.extractCandidate(found, run[:len(i.Prefix)+next])
+		run = run[len(i.Prefix)+next:]
+	}
+}
+
+// extractCandidate appends the value of candidate to found if it decodes
+func (i *IdEncoder) extractCandidate(found []uint64, candidate string) []uint64 {
+	// a separator can't end a code, but punctuation such as a dash may follow one
+	if i.GroupSize > 0 {
+		candidate = strings.TrimRight(candidate, string(i.separator()))
+	}
+	min := len(i.Prefix) + i.DecoyPrefixLen + 2
+	max := i.encodedWidth(i.maxDecodeLength() - 1)
+	if len(candidate) < min || len(candidate) > max {
+		return found
+	}
+	if decoded, err := i.Decode(candidate); err == nil {
+		found = append(found, decoded)
+	}
+	return found
+}
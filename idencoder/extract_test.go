@@ -0,0 +1,58 @@
+package idencoder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractAll(t *testing.T) {
+	configs := map[string]func(i *IdEncoder){
+		"plain":       func(i *IdEncoder) {},
+		"prefix":      func(i *IdEncoder) { i.Prefix = "u_" },
+		"grouped":     func(i *IdEncoder) { i.GroupSize = 3 },
+		"fold case":   func(i *IdEncoder) { i.FoldCase = true },
+		"pad char":    func(i *IdEncoder) { i.PadChar = '.' },
+		"zero symbol": func(i *IdEncoder) { i.ZeroSymbol = '_' },
+		"everything": func(i *IdEncoder) {
+			i.Prefix = "id:"
+			i.GroupSize = 4
+			i.FoldCase = true
+			i.ChecksumPlacement = ChecksumTrailing
+		},
+	}
+	values := []uint64{0, 1000, 123456789, 1 << 40}
+	for name, configure := range configs {
+		i := testEncoder()
+		configure(i)
+		var codes []string
+		for _, n := range values {
+			code, err := i.Encode(n, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.FoldCase {
+				code = i.Prefix + strings.ToUpper(code[len(i.Prefix):])
+			}
+			codes = append(codes, code)
+		}
+		// the noise uses no alphabet characters, apart from one run that fails its checksum
+		text := "lol " + codes[0] + ", oil: (" + codes[1] + ") 10 " + codes[2] + "-- ill " +
+			"https://x.io/" + codes[3] + "?q=1 zzzzzzz"
+		if got := i.ExtractAll(text); !reflect.DeepEqual(got, values) {
+			t.Errorf("%s: ExtractAll(%q) = %v, want %v", name, text, got, values)
+		}
+	}
+}
+
+func TestExtractAllPrefixSplitsRuns(t *testing.T) {
+	i := testEncoder()
+	i.Prefix = "u_"
+	a, _ := i.Encode(1, MinLength)
+	b, _ := i.Encode(2, MinLength)
+	// the prefix marks where each code starts, even with no gap before it
+	text := "see" + a + b + " u_"
+	if got, want := i.ExtractAll(text), []uint64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractAll(%q) = %v, want %v", text, got, want)
+	}
+}
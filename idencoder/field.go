@@ -0,0 +1,53 @@
+package idencoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Align selects which side of a fixed-width field a code is placed against
+type Align int
+
+const (
+	// AlignLeft places the code at the start of the field, followed by fill
+	AlignLeft Align = iota
+	// AlignRight places the code at the end of the field, preceded by fill
+	AlignRight
+)
+
+// EncodeField encodes n like Encode and pads the result with fill to exactly
+// fieldWidth characters, for fixed-width records. Unlike minLength padding, the
+// fill is not part of the code; DecodeField strips it. fill must not be a
+// character that can appear in a code: one of the alphabet or Prefix, the
+// ZeroSymbol, the PadChar, or the separator when grouping. An error is returned if
+// the code is wider than the field rather than overflowing it.
+func (i *IdEncoder) EncodeField(n, minLength uint64, fieldWidth int, align Align, fill byte) (string, error) {
+	if bytes.IndexByte(i.Alphabet, fill) >= 0 || strings.IndexByte(i.Prefix, fill) >= 0 ||
+		i.ZeroSymbol != 0 && fill == i.ZeroSymbol || i.PadChar != 0 && fill == i.PadChar ||
+		i.GroupSize > 0 && fill == i.separator() {
+		return "", &IdEncoderError{
+			Message: fmt.Sprintf("Fill %q must not be a character of the code", fill),
+		}
+	}
+	encoded, err := i.Encode(n, minLength)
+	if err != nil {
+		return "", err
+	}
+	if len(encoded) > fieldWidth {
+		return "", &IdEncoderError{
+			Message: fmt.Sprintf("Encoded value %q exceeds field width %d", encoded, fieldWidth),
+		}
+	}
+	padding := strings.Repeat(string(fill), fieldWidth-len(encoded))
+	if align == AlignRight {
+		return padding + encoded, nil
+	}
+	return encoded + padding, nil
+}
+
+// DecodeField strips fill from both ends of a field produced by EncodeField and
+// decodes the code within it
+func (i *IdEncoder) DecodeField(field string, fill byte) (uint64, error) {
+	return i.Decode(strings.Trim(field, string(fill)))
+}
@@ -0,0 +1,74 @@
+package idencoder
+
+import "testing"
+
+func TestEncodeField(t *testing.T) {
+	i := testEncoder()
+	code, err := i.Encode(1000, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		align Align
+		want  string
+	}{
+		{AlignLeft, code + "    "},
+		{AlignRight, "    " + code},
+	}
+	for _, tt := range tests {
+		field, err := i.EncodeField(1000, MinLength, len(code)+4, tt.align, ' ')
+		if err != nil || field != tt.want {
+			t.Errorf("EncodeField(align %d) = %q, %v, want %q", tt.align, field, err, tt.want)
+		}
+		if decoded, err := i.DecodeField(field, ' '); err != nil || decoded != 1000 {
+			t.Errorf("DecodeField(%q) = %d, %v, want 1000", field, decoded, err)
+		}
+	}
+	if field, err := i.EncodeField(1000, MinLength, len(code), AlignRight, '*'); err != nil || field != code {
+		t.Errorf("EncodeField at the code's width = %q, %v, want %q", field, err, code)
+	}
+	if field, err := i.EncodeField(1000, MinLength, len(code)-1, AlignLeft, ' '); err == nil {
+		t.Errorf("EncodeField narrower than the code = %q, want an error", field)
+	}
+	if field, err := i.EncodeField(1000, MinLength, 20, AlignLeft, i.Alphabet[0]); err == nil {
+		t.Errorf("EncodeField with an alphabet fill = %q, want an error", field)
+	}
+}
+
+// A fill that can appear in the code would be stripped along with the padding
+func TestEncodeFieldRejectsCodeCharacters(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(i *IdEncoder)
+		fill      byte
+	}{
+		{"zero symbol", func(i *IdEncoder) { i.ZeroSymbol = '!' }, '!'},
+		{"pad char", func(i *IdEncoder) { i.PadChar = '.' }, '.'},
+		{"separator", func(i *IdEncoder) { i.GroupSize = 3 }, DefaultSeparator},
+		{"prefix", func(i *IdEncoder) { i.Prefix = "_x" }, '_'},
+	}
+	for _, tt := range tests {
+		i := testEncoder()
+		tt.configure(i)
+		if field, err := i.EncodeField(0, MinLength, 20, AlignRight, tt.fill); err == nil {
+			t.Errorf("%s: EncodeField with fill %q = %q, want an error", tt.name, tt.fill, field)
+		}
+		// any other fill still round-trips
+		field, err := i.EncodeField(0, MinLength, 20, AlignLeft, ' ')
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if decoded, err := i.DecodeField(field, ' '); err != nil || decoded != 0 {
+			t.Errorf("%s: DecodeField(%q) = %d, %v, want 0", tt.name, field, decoded, err)
+		}
+	}
+	// the separator is only part of the code when grouping
+	i := testEncoder()
+	field, err := i.EncodeField(1000, MinLength, 20, AlignLeft, DefaultSeparator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := i.DecodeField(field, DefaultSeparator); err != nil || decoded != 1000 {
+		t.Errorf("DecodeField(%q) = %d, %v, want 1000", field, decoded, err)
+	}
+}
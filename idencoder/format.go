@@ -0,0 +1,163 @@
+package idencoder
+
+import (
+	"bytes"
+	"strings"
+)
+
+// formatted reports whether any option changes the presentation of encoded values
+// from the raw layout
+func (i *IdEncoder) formatted() bool {
+	return i.Prefix != "" || i.DecoyPrefixLen > 0 || i.GroupSize > 0 || i.ChecksumPlacement != ChecksumLeading
+}
+
+// format applies the presentational options to a raw encoded value, which is the
+// checksum character followed by the data characters, padding included. The data
+// characters are split into groups of GroupSize counting from the right, joined by
+// Separator, and the checksum is moved after them if ChecksumPlacement is
+// ChecksumTrailing, or among them, without changing the groups' boundaries, if it
+// is ChecksumInterleaved. The result is prefixed with Prefix and DecoyPrefixLen
+// decoy characters, so the canonical layout is prefix, decoys, checksum, padding,
+// data; or prefix, decoys, padding, data, checksum.
+func (i *IdEncoder) format(raw string) string {
+	var sb strings.Builder
+	sb.Grow(len(i.Prefix) + i.DecoyPrefixLen + len(raw) + len(raw)/(i.GroupSize+1))
+	sb.WriteString(i.Prefix)
+	if i.DecoyPrefixLen > 0 {
+		sb.Write(i.appendDecoys(make([]byte, 0, i.DecoyPrefixLen), raw))
+	}
+	data := raw[1:]
+	pos := i.checksumPosition(len(data))
+	if pos < 0 {
+		sb.WriteByte(raw[0])
+	}
+	for idx := 0; idx < len(data); idx++ {
+		if i.GroupSize > 0 && idx > 0 && (len(data)-idx)%i.GroupSize == 0 {
+			sb.WriteByte(i.separator())
+		}
+		if idx == pos {
+			sb.WriteByte(raw[0])
+		}
+		sb.WriteByte(data[idx])
+	}
+	if pos == len(data) {
+		sb.WriteByte(raw[0])
+	}
+	return sb.String()
+}
+
+// checksumPosition returns the index of the data character that the checksum is
+// placed before, or dataLen if it follows all of them. A leading checksum returns
+// -1, as it precedes the data outside any group.
+func (i *IdEncoder) checksumPosition(dataLen int) int {
+	switch i.ChecksumPlacement {
+	case ChecksumTrailing:
+		return dataLen
+	case ChecksumInterleaved:
+		return (dataLen + 1) / 2
+	}
+	return -1
+}
+
+// parse reverses format, returning the raw encoded value. The stages are undone in
+// order: the prefix is stripped, characters outside the alphabet are case folded if
+// FoldCase is set, separators are removed wherever they appear, the decoy
+// characters are stripped and verified, and a trailing or interleaved checksum is
+// moved back to the front.
+func (i *IdEncoder) parse(s string) (string, error) {
+	if !strings.HasPrefix(s, i.Prefix) {
+		return "", ErrMissingPrefix
+	}
+	s = s[len(i.Prefix):]
+	if i.FoldCase || i.GroupSize > 0 {
+		if len(s) > 2*i.maxDecodeLength() {
+			return "", ErrTooLong
+		}
+		raw := make([]byte, 0, len(s))
+		for idx := 0; idx < len(s); idx++ {
+			c := s[idx]
+			if i.GroupSize > 0 && c == i.separator() {
+				continue
+			}
+			if i.FoldCase && bytes.IndexByte(i.Alphabet, c) < 0 {
+				c = swapCase(c)
+			}
+			raw = append(raw, c)
+		}
+		s = string(raw)
+	}
+	// moving the checksum copies s, so it is bounded first
+	if len(s) > i.DecoyPrefixLen+i.maxDecodeLength() {
+		return "", ErrTooLong
+	}
+	var decoys string
+	if i.DecoyPrefixLen > 0 {
+		if len(s) < i.DecoyPrefixLen+1 {
+			return "", ErrTooShort
+		}
+		decoys, s = s[:i.DecoyPrefixLen], s[i.DecoyPrefixLen:]
+	}
+	if pos := i.checksumPosition(len(s) - 1); pos >= 0 && len(s) > 0 {
+		s = s[pos:pos+1] + s[:pos] + s[pos+1:]
+	}
+	if i.DecoyPrefixLen > 0 && string(i.appendDecoys(make([]byte, 0, i.DecoyPrefixLen), s)) != decoys {
+		return "", ErrDecoyMismatch
+	}
+	return s, nil
+}
+
+// originalIndex maps an index within the raw encoded value parsed from s back to
+// the corresponding index within s
+func (i *IdEncoder) originalIndex(s string, rawIndex int) int {
+	if pos := i.checksumPosition(i.rawLength(s) - 1); pos >= 0 {
+		switch {
+		case rawIndex == 0:
+			rawIndex = pos
+		case rawIndex <= pos:
+			rawIndex--
+		}
+	}
+	idx := len(i.Prefix)
+	rawIndex += i.DecoyPrefixLen
+	for ; idx < len(s); idx++ {
+		if i.GroupSize > 0 && s[idx] == i.separator() {
+			continue
+		}
+		if rawIndex == 0 {
+			break
+		}
+		rawIndex--
+	}
+	return idx
+}
+
+// rawLength returns the number of characters of s that make up the raw encoded
+// value, excluding the prefix, separators and decoys
+func (i *IdEncoder) rawLength(s string) int {
+	n := -i.DecoyPrefixLen
+	for idx := len(i.Prefix); idx < len(s); idx++ {
+		if i.GroupSize <= 0 || s[idx] != i.separator() {
+			n++
+		}
+	}
+	return n
+}
+
+// separator returns the configured Separator, or DefaultSeparator
+func (i *IdEncoder) separator() byte {
+	if i.Separator != 0 {
+		return i.Separator
+	}
+	return DefaultSeparator
+}
+
+// swapCase returns the ASCII letter c in the opposite case, or c unchanged if it isn't a letter
+func swapCase(c byte) byte {
+	switch {
+	case 'a' <= c && c <= 'z':
+		return c - 'a' + 'A'
+	case 'A' <= c && c <= 'Z':
+		return c - 'A' + 'a'
+	}
+	return c
+}
@@ -0,0 +1,138 @@
+package idencoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGroupingWithPrefixAndChecksum(t *testing.T) {
+	plain := testEncoder()
+	i := testEncoder()
+	i.Prefix = "ab"
+	i.GroupSize = 4
+	trailing := testEncoder()
+	trailing.Prefix = "ab"
+	trailing.GroupSize = 4
+	trailing.ChecksumPlacement = ChecksumTrailing
+	// without scrambling, 123 is two data characters, so minLength sets the width
+	for _, e := range []*IdEncoder{plain, i, trailing} {
+		e.BlockSize = 0
+	}
+	// groups count from the right of the data; the prefix and checksum stay whole
+	// and outside the groups
+	tests := []struct {
+		minLength         uint64
+		leading, trailing string
+	}{
+		{4, "abcdddd", "abddddc"},
+		{5, "abcd-dddd", "abd-ddddc"},
+		{8, "abcdddd-dddd", "abdddd-ddddc"},
+		{9, "abcd-dddd-dddd", "abd-dddd-ddddc"},
+	}
+	for _, tt := range tests {
+		raw, err := plain.Encode(123, tt.minLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, enc := range []struct {
+			i      *IdEncoder
+			layout string
+		}{{i, tt.leading}, {trailing, tt.trailing}} {
+			code, err := enc.i.Encode(123, tt.minLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fill(enc.layout, raw); code != want {
+				t.Errorf("Encode(123, %d) = %q, want %q", tt.minLength, code, want)
+			}
+			if decoded, err := enc.i.Decode(code); err != nil || decoded != 123 {
+				t.Errorf("Decode(%q) = %d, %v, want 123", code, decoded, err)
+			}
+		}
+	}
+}
+
+func TestChecksumInterleaved(t *testing.T) {
+	plain := testEncoder()
+	i := testEncoder()
+	i.ChecksumPlacement = ChecksumInterleaved
+	for _, n := range testValues() {
+		for _, minLength := range []uint64{1, 2, 5, 6, 9, 14} {
+			raw, err := plain.Encode(n, minLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, err := i.Encode(n, minLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the checksum goes before data character (n+1)/2
+			data := raw[1:]
+			pos := (len(data) + 1) / 2
+			if want := data[:pos] + raw[:1] + data[pos:]; code != want {
+				t.Errorf("Encode(%d, %d) = %q, want %q", n, minLength, code, want)
+			}
+			if decoded, err := i.Decode(code); err != nil || decoded != n {
+				t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+			}
+		}
+	}
+
+	// the checksum sits within the groups without moving their boundaries
+	i.GroupSize = 4
+	i.BlockSize = 0
+	plain.BlockSize = 0
+	for _, tt := range []struct {
+		minLength uint64
+		layout    string
+	}{
+		{4, "ddcdd"},
+		{5, "d-ddcdd"},
+		{8, "dddd-cdddd"},
+		{9, "d-dddd-cdddd"},
+	} {
+		raw, _ := plain.Encode(123, tt.minLength)
+		code, err := i.Encode(123, tt.minLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fill(tt.layout, raw); code != want {
+			t.Errorf("grouped Encode(123, %d) = %q, want %q", tt.minLength, code, want)
+		}
+		if decoded, err := i.Decode(code); err != nil || decoded != 123 {
+			t.Errorf("Decode(%q) = %d, %v, want 123", code, decoded, err)
+		}
+	}
+
+	// stray padding shifts the checksum along with its position, so only
+	// CheckPadded notices it
+	i = testEncoder()
+	i.ChecksumPlacement = ChecksumInterleaved
+	code, _ := i.Encode(1000, 8)
+	extra := string(i.padChar()) + code
+	if decoded, err := i.Decode(extra); err != nil || decoded != 1000 {
+		t.Errorf("Decode(%q) = %d, %v, want 1000", extra, decoded, err)
+	}
+	i.ChecksumMode = CheckPadded
+	code, _ = i.Encode(1000, 8)
+	extra = string(i.padChar()) + code
+	if _, err := i.Decode(extra); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("CheckPadded Decode(%q) with an added pad character error %v, want ErrChecksumMismatch", extra, err)
+	}
+}
+
+// fill replaces the 'c' of layout with the checksum of raw, and each 'd' with the
+// next data character of raw
+func fill(layout, raw string) string {
+	out := []byte(layout)
+	data := raw[1:]
+	for idx, c := range out {
+		switch c {
+		case 'c':
+			out[idx] = raw[0]
+		case 'd':
+			out[idx], data = data[0], data[1:]
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,44 @@
+package idencoder
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// ErrExhausted is returned once a Generator has handed out every value
+var ErrExhausted = &IdEncoderError{Message: "Generator exhausted"}
+
+// Generator hands out codes for consecutive values from a counter. It is safe for
+// concurrent use, and never hands out the same value twice.
+type Generator struct {
+	// counter is first so it is 64-bit aligned for atomic access on 32-bit platforms
+	counter   uint64
+	exhausted uint32
+	enc       *IdEncoder
+}
+
+// NewGenerator returns a Generator whose first code is the encoding of start
+func NewGenerator(enc *IdEncoder, start uint64) *Generator {
+	return &Generator{counter: start, enc: enc}
+}
+
+// Next encodes the next value with the encoder's MinLength. If encoding fails, the
+// value is not handed out again. Returns ErrExhausted after math.MaxUint64 has
+// been handed out.
+func (g *Generator) Next() (string, error) {
+	for {
+		n := atomic.LoadUint64(&g.counter)
+		if atomic.LoadUint32(&g.exhausted) != 0 {
+			return "", ErrExhausted
+		}
+		if n == math.MaxUint64 {
+			if !atomic.CompareAndSwapUint32(&g.exhausted, 0, 1) {
+				return "", ErrExhausted
+			}
+			return g.enc.Encode(n, g.enc.minLength())
+		}
+		if atomic.CompareAndSwapUint64(&g.counter, n, n+1) {
+			return g.enc.Encode(n, g.enc.minLength())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package idencoder
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+// Run with -race: many goroutines draw from one Generator at once.
+func TestGeneratorConcurrentUnique(t *testing.T) {
+	const goroutines, perGoroutine = 64, 500
+	i := testEncoder()
+	g := NewGenerator(i, 1000)
+	results := make([][]string, goroutines)
+	var wg sync.WaitGroup
+	for w := 0; w < goroutines; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			codes := make([]string, 0, perGoroutine)
+			for k := 0; k < perGoroutine; k++ {
+				code, err := g.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				codes = append(codes, code)
+			}
+			results[w] = codes
+		}(w)
+	}
+	wg.Wait()
+	seen := make(map[uint64]bool, goroutines*perGoroutine)
+	for _, codes := range results {
+		for _, code := range codes {
+			n, err := i.Decode(code)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", code, err)
+			}
+			if seen[n] {
+				t.Fatalf("%d was handed out twice", n)
+			}
+			seen[n] = true
+		}
+	}
+	// every value from start was handed out exactly once
+	for n := uint64(1000); n < 1000+goroutines*perGoroutine; n++ {
+		if !seen[n] {
+			t.Errorf("%d was never handed out", n)
+		}
+	}
+}
+
+func TestGeneratorExhausted(t *testing.T) {
+	i := testEncoder()
+	g := NewGenerator(i, math.MaxUint64-1)
+	for _, want := range []uint64{math.MaxUint64 - 1, math.MaxUint64} {
+		code, err := g.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := i.Decode(code); n != want {
+			t.Errorf("Next() = %q for %d, want %d", code, n, want)
+		}
+	}
+	for k := 0; k < 2; k++ {
+		if code, err := g.Next(); !errors.Is(err, ErrExhausted) {
+			t.Errorf("Next() after the last value = %q, %v, want ErrExhausted", code, err)
+		}
+	}
+}
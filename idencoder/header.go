@@ -0,0 +1,88 @@
+package idencoder
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// headerVersion is the first character of every self-describing header
+	headerVersion = '1'
+	// headerFieldLen is the number of base 36 digits in each header field
+	headerFieldLen = 2
+	// headerLen is the length of a self-describing header: the version character
+	// and the radix, block size and checksum fields
+	headerLen = 1 + 3*headerFieldLen
+)
+
+// EncodeSelfDescribing encodes n like Encode, prepending a header of the alphabet
+// length, block size and checksum modulus, so the structure of stored codes can
+// be checked with ParseHeader long after the configuration is forgotten. The
+// header reveals nothing of the alphabet itself, which is still required to
+// decode the value.
+func (i *IdEncoder) EncodeSelfDescribing(n, minLength uint64) (string, error) {
+	header, err := i.header()
+	if err != nil {
+		return "", err
+	}
+	encoded, err := i.Encode(n, minLength)
+	if err != nil {
+		return "", err
+	}
+	return header + encoded, nil
+}
+
+// DecodeSelfDescribing converts a string produced by EncodeSelfDescribing back to
+// an integer, returning an error if its header doesn't match the encoder
+func (i *IdEncoder) DecodeSelfDescribing(s string) (uint64, error) {
+	header, err := i.header()
+	if err != nil {
+		return 0, err
+	}
+	if len(s) < headerLen || s[:headerLen] != header {
+		return 0, &IdEncoderError{
+			Message: "Header doesn't match the encoder",
+		}
+	}
+	return i.Decode(s[headerLen:])
+}
+
+// ParseHeader reads the header of a string produced by EncodeSelfDescribing,
+// returning the alphabet length, block size and checksum modulus it was encoded with
+func ParseHeader(s string) (radix, blockSize, checksumMod int, err error) {
+	if len(s) < headerLen || s[0] != headerVersion {
+		return 0, 0, 0, &IdEncoderError{
+			Message: "Missing or unsupported header",
+		}
+	}
+	var fields [3]int
+	for idx := range fields {
+		start := 1 + idx*headerFieldLen
+		field, err := strconv.ParseUint(s[start:start+headerFieldLen], 36, 16)
+		if err != nil {
+			return 0, 0, 0, &IdEncoderError{
+				Message: fmt.Sprintf("Invalid header %q", s[:headerLen]),
+			}
+		}
+		fields[idx] = int(field)
+	}
+	return fields[0], fields[1], fields[2], nil
+}
+
+// header returns the self-describing header for the encoder's configuration
+func (i *IdEncoder) header() (string, error) {
+	header := []byte{headerVersion}
+	for _, field := range []uint64{uint64(len(i.Alphabet)), uint64(i.BlockSize), uint64(i.Checksum)} {
+		digits := strconv.FormatUint(field, 36)
+		if len(digits) > headerFieldLen {
+			return "", &IdEncoderError{
+				Message: fmt.Sprintf("%d is too large for a header field", field),
+			}
+		}
+		for pad := len(digits); pad < headerFieldLen; pad++ {
+			header = append(header, '0')
+		}
+		header = append(header, digits...)
+	}
+	return string(header), nil
+}
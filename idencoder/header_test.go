@@ -0,0 +1,43 @@
+package idencoder
+
+import "testing"
+
+func TestSelfDescribingRoundTrip(t *testing.T) {
+	wide := testEncoder()
+	wide.Alphabet = Alphabet(URLSafeAlphabet)
+	wide.BlockSize = 64
+	wide.Checksum = 61
+	for _, i := range []*IdEncoder{testEncoder(), wide} {
+		for _, n := range []uint64{0, 1000, 1<<64 - 1} {
+			s, err := i.EncodeSelfDescribing(n, MinLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			radix, blockSize, checksumMod, err := ParseHeader(s)
+			if err != nil || radix != len(i.Alphabet) || blockSize != int(i.BlockSize) || checksumMod != int(i.Checksum) {
+				t.Errorf("ParseHeader(%q) = %d, %d, %d, %v, want %d, %d, %d", s, radix, blockSize, checksumMod, err, len(i.Alphabet), i.BlockSize, i.Checksum)
+			}
+			if decoded, err := i.DecodeSelfDescribing(s); err != nil || decoded != n {
+				t.Errorf("DecodeSelfDescribing(%q) = %d, %v, want %d", s, decoded, err, n)
+			}
+			// the header doesn't match an encoder with other parameters
+			if _, err := wide.DecodeSelfDescribing(s); i != wide && err == nil {
+				t.Errorf("DecodeSelfDescribing(%q) accepted another encoder's header", s)
+			}
+		}
+	}
+	s, _ := testEncoder().EncodeSelfDescribing(1000, MinLength)
+	if s[:headerLen] != "10v0o0t" {
+		t.Errorf("header %q, want \"10v0o0t\" for 31 characters, 24 bits and checksum 29", s[:headerLen])
+	}
+	for _, bad := range []string{"", "1", "20v0o0t", "10v!o0t"} {
+		if _, _, _, err := ParseHeader(bad); err == nil {
+			t.Errorf("ParseHeader(%q) succeeded", bad)
+		}
+	}
+	huge := testEncoder()
+	huge.Checksum = 36 * 36
+	if s, err := huge.EncodeSelfDescribing(1, MinLength); err == nil {
+		t.Errorf("EncodeSelfDescribing with Checksum %d = %q, want an error", huge.Checksum, s)
+	}
+}
@@ -18,6 +18,15 @@ bits are reversed. Any bits higher than `BlockSize` will remain as is.
 `BlockSize` of 0 will leave all bits unaffected and the algorithm will simply
 be converting your integer to a different base.
 
+Because the bits are reversed before conversion to the base of the alphabet,
+the unaffected high bits only map to a stable run of leading characters when
+the alphabet length is a power of two. For any other alphabet length, the
+boundary between shuffled and unshuffled bits falls in the middle of an
+output character and carries from the shuffled portion spill into the high
+characters. Setting `AlignBlock` reverses the lowest base-N digits covering
+`BlockSize` bits instead of the bits themselves, so the value above the block
+always maps directly to the leading characters of the encoded value.
+
 ## Common usage
 
 ### URL shortening & obfuscation
@@ -33,6 +42,15 @@ The module supports both encoding and decoding of values. The `minLength`
 parameter allows you to pad the encoded value if you want it to be a specific
 length.
 
+## Portability
+
+Encoding is portable: the same configuration produces byte-identical codes on
+32-bit and 64-bit platforms of either byte order. Values are always handled as
+uint64, never int, and wherever integers and bytes meet (the keyed scramble,
+associated data digests, blobs and EncodeToBytes) the byte order is fixed as
+big-endian rather than taken from the host. Seeded alphabets use math/rand,
+whose sequences for a given seed don't vary by platform.
+
 ## Provenance
 
 Original Author (Python): [Michael Fogleman](http://code.activestate.com/recipes/576918/)
@@ -41,6 +59,13 @@ License: [MIT](https://opensource.org/licenses/MIT)
 Modified Python version from which this version is ported
 URL: https://github.com/brnt/idencoder
 
+Codes have not been verified against the Python version, so don't assume codes
+stored by one decode with the other. With a `minLength` of 0, zero encodes to the
+checksum character alone. Because padding is the first character of the
+alphabet, which is also the digit zero, data made up entirely of that character
+decodes as zero however long it is. Set `ZeroSymbol` to tell zero
+apart from padding.
+
 Repo: https://github.com/brnt/idencoder-go
 
 
@@ -49,7 +74,11 @@ package idencoder
 
 import (
 	"bytes"
+	"crypto/cipher"
+	"errors"
 	"fmt"
+	"math"
+	"math/bits"
 )
 
 // Alphabet is a set of characters to be used in an encoded value
@@ -61,10 +90,104 @@ type BlockSize uint64
 // Checksum validates scramble/unscramble sub-operations
 type Checksum uint64
 
+// ChecksumMode selects what the checksum character is computed from. The modes are
+// mutually exclusive.
+type ChecksumMode int
+
+const (
+	// CheckValue computes the checksum from the integer value, modulo Checksum
+	CheckValue ChecksumMode = iota
+	// CheckOutput computes a Luhn mod N check character over the encoded data
+	// characters, where N is the alphabet length. This catches single character
+	// errors and most transpositions of adjacent characters. Checksum is not used.
+	// Padding characters don't affect the check character.
+	CheckOutput
+	// CheckPadded computes the checksum from the integer value and the number of
+	// data characters, modulo Checksum, so adding or removing padding invalidates
+	// the checksum. This is most useful with StrictLength or FixedWidth.
+	CheckPadded
+	// CheckScrambled computes the checksum from the scrambled value, the bits
+	// actually encoded in the data characters, modulo Checksum. Unlike CheckValue,
+	// the checksum character doesn't reveal the integer value modulo Checksum, and
+	// the same value gets unrelated checksums under different scrambles. Both
+	// detect tampering with the data characters equally well, provided Checksum
+	// shares no factor with the alphabet length, which Validate requires; otherwise
+	// only the last data characters would be checked.
+	CheckScrambled
+)
+
+// ChecksumPlacement selects where the checksum character appears in an encoded value
+type ChecksumPlacement int
+
+const (
+	// ChecksumLeading places the checksum first, before any padding and the data
+	ChecksumLeading ChecksumPlacement = iota
+	// ChecksumTrailing places the checksum last, after any padding and the data
+	ChecksumTrailing
+	// ChecksumInterleaved places the checksum among the data characters, before
+	// the data character at index (n+1)/2 for n data characters, padding included.
+	// Decode finds it again from the length alone. The checksum no longer stands
+	// out as the first or last character, but anyone comparing a few codes of the
+	// same length can still locate it, so this is cosmetic rather than added
+	// protection. Nor does it catch stray padding: a pad character added to an
+	// even number of data characters moves the checksum position along with it.
+	// Use CheckPadded for that.
+	ChecksumInterleaved
+)
+
+// BitOrder selects the orientation of the bit reversal within the scramble block
+type BitOrder int
+
+const (
+	// LSBFirst reverses the block as a whole, so its lowest bit becomes its highest
+	LSBFirst BitOrder = iota
+	// MSBFirst reverses the bits within each byte of the block, reading each byte
+	// most significant bit first. This is the reversal of LSBFirst with the block's
+	// bytes in the opposite order. Bits above the last whole byte of the block are
+	// reversed among themselves. For a BlockSize of 8 or less, both orders match.
+	MSBFirst
+)
+
 type IdEncoderError struct {
 	Message string
 }
 
+// Errors returned when decoding. Errors may be wrapped or, like InvalidCharacterError,
+// carry more detail, so test for them with errors.Is.
+var (
+	// ErrChecksumMismatch is returned when an encoded value's checksum character doesn't match its value
+	ErrChecksumMismatch = &IdEncoderError{Message: "Checksum mismatch"}
+	// ErrTooShort is returned for input too short to hold a checksum
+	ErrTooShort = &IdEncoderError{Message: "Encoded value too short"}
+	// ErrTooLong is returned for input longer than MaxDecodeLength
+	ErrTooLong = &IdEncoderError{Message: "Encoded value too long"}
+	// ErrWrongLength is returned under StrictLength for input that isn't exactly the expected width
+	ErrWrongLength = &IdEncoderError{Message: "Encoded value has the wrong length"}
+	// ErrMissingPrefix is returned for input that doesn't start with the configured Prefix
+	ErrMissingPrefix = &IdEncoderError{Message: "Encoded value is missing its prefix"}
+	// ErrNotCanonical is returned when strict decoding input that isn't exactly the
+	// string Encode produces for its value, for example because of extra padding
+	ErrNotCanonical = &IdEncoderError{Message: "Encoded value is not canonical"}
+	// ErrOverflow is returned when a decoded value doesn't fit in the requested integer type
+	ErrOverflow = &IdEncoderError{Message: "Decoded value overflows"}
+	// ErrDecoyMismatch is returned when the decoy characters of an encoded value
+	// don't match those derived from the rest of it
+	ErrDecoyMismatch = &IdEncoderError{Message: "Decoy characters don't match"}
+)
+
+// ErrInvalidCharacter is the category of errors for input containing a character
+// that is not in the alphabet. Use errors.Is to test for it.
+var ErrInvalidCharacter = &IdEncoderError{Message: "Invalid character"}
+
+// InvalidCharacterError reports the position of a character that is not in the alphabet.
+// For a RuneEncoder, Index counts runes and Rune holds the character, which is
+// also in Char if it is ASCII.
+type InvalidCharacterError struct {
+	Index int
+	Char  byte
+	Rune  rune
+}
+
 // Default values for encoder/decoders
 const (
 	// DefaultAlphabet SHOULD NOT be used in production!!! This value
@@ -75,6 +198,8 @@ const (
 	DefaultBlockSize = 24
 	DefaultChecksum  = 29
 	MinLength        = 5
+	DefaultMaxLength = 64
+	DefaultSeparator = '-'
 )
 
 // IdEncoder contains the various values for an encoder/decoder.
@@ -82,73 +207,716 @@ type IdEncoder struct {
 	Alphabet  Alphabet
 	BlockSize BlockSize
 	Checksum  Checksum
+	// ChecksumMode selects how the checksum character is computed
+	ChecksumMode ChecksumMode
+	// ChecksumName selects a checksum registered with RegisterChecksum, overriding
+	// ChecksumMode. Validate reports names that aren't registered.
+	ChecksumName string
+	// ChecksumPlacement selects whether the checksum character precedes, follows
+	// or is interleaved among the padding and data characters
+	ChecksumPlacement ChecksumPlacement
+	// AlignBlock shuffles whole output digits rather than bits, so the
+	// unshuffled high portion of a value maps cleanly to leading characters
+	AlignBlock bool
+	// BitOrder selects how bits are reversed within the block. AlignBlock and
+	// scramble keys ignore it.
+	BitOrder BitOrder
+	// Salt is XORed into each value before it is scrambled, so encoders that
+	// differ only by Salt produce different codes for the same value
+	Salt uint64
+	// MaxDecodeLength is the longest input Decode will accept, excluding any
+	// formatting. If 0, the longer of an encoded math.MaxUint64 and a MaxLength
+	// padded value, including the checksum, is used. Encode refuses to produce
+	// longer values.
+	MaxDecodeLength int
+	// MaxLength is the longest data portion (excluding checksum) Encode will produce.
+	// If 0, DefaultMaxLength is used.
+	MaxLength int
+	// MinLength is the encoder's default minimum data length (excluding checksum).
+	// If 0, the package MinLength is used.
+	MinLength uint64
+	// StrictLength makes Decode reject any input that isn't exactly MinLength
+	// characters plus the checksum, for fixed-width deployments
+	StrictLength bool
+	// FixedWidth treats the minLength passed to Encode as an exact width, returning
+	// an error rather than growing the output when a value doesn't fit
+	FixedWidth bool
+	// Strict makes Encode verify that each encoded value decodes back to the
+	// original value, so misconfiguration surfaces at encode time
+	Strict bool
+	// StrictCanonical makes Decode reject, with ErrNotCanonical, any input that
+	// isn't exactly the string Encode produces for its value with MinLength, such
+	// as input with extra padding, the wrong case or misplaced separators. By
+	// default such input is accepted; DecodeCanonical gives its canonical form.
+	StrictCanonical bool
+	// Prefix is prepended to every encoded value and required when decoding
+	Prefix string
+	// DecoyPrefixLen is the number of decoy characters placed between the prefix
+	// and the checksum. Decoys are derived from a hash of the rest of the encoded
+	// value rather than being padding, so short codes look random, and Decode
+	// recomputes them to detect tampering.
+	DecoyPrefixLen int
+	// GroupSize splits the data characters into groups of this size, counting
+	// from the right, joined by Separator. Groups never include the prefix,
+	// decoys or checksum, and no separator is placed between them and the data,
+	// so only the leftmost group may be short. For example, with Prefix "u_" and
+	// a GroupSize of 4, the checksum 'g' and data "333r8r7s9" are formatted as
+	// "u_g3-33r8-r7s9", or "u_3-33r8-r7s9g" with a trailing checksum. Decode
+	// removes separators wherever they appear. If 0, the data characters are not
+	// grouped.
+	GroupSize int
+	// Separator joins groups of data characters. If 0, DefaultSeparator is used.
+	// It must not be a character of the alphabet.
+	Separator byte
+	// FoldCase makes Decode accept characters in either case
+	FoldCase bool
+	// ZeroSymbol, if set, replaces the final data character when the data is zero,
+	// so zero is distinct from padding. Decode then rejects data made up only of
+	// padding with ErrNotCanonical. It must not be a character of the alphabet.
+	ZeroSymbol byte
+	// PadChar, if set, is used for the padding that brings data up to minLength,
+	// instead of the first character of the alphabet. It must either be that
+	// character or not be in the alphabet at all, in which case Decode strips it
+	// before converting the data.
+	PadChar byte
+	// Blocklist lists substrings, such as offensive words, that EncodeSafe avoids
+	// producing. Matching ignores case.
+	Blocklist []string
+
+	scrambleKey cipher.Block
+	// checksumFunc is the custom checksum named by ChecksumName, resolved by New
+	checksumFunc ChecksumFunc
+	// lookup maps characters to their alphabet positions, built by New
+	lookup *byteTable
+	// aad is a digest of associated data mixed into the checksum
+	aad uint64
 }
 
 func (e *IdEncoderError) Error() string {
 	return fmt.Sprintf("IdEncoder error: %s", e.Message)
 }
 
+func (e *InvalidCharacterError) Error() string {
+	if e.Rune != 0 {
+		return fmt.Sprintf("IdEncoder error: Invalid character %q at index %d", e.Rune, e.Index)
+	}
+	return fmt.Sprintf("IdEncoder error: Invalid character %q at index %d", e.Char, e.Index)
+}
+
+// Is reports whether target is ErrInvalidCharacter
+func (e *InvalidCharacterError) Is(target error) bool {
+	return target == ErrInvalidCharacter
+}
+
+// invalidCharacter returns the InvalidCharacterError in err's chain, or nil
+func invalidCharacter(err error) *InvalidCharacterError {
+	if err == nil {
+		// avoid allocating the target of errors.As on the success path
+		return nil
+	}
+	var invalid *InvalidCharacterError
+	if errors.As(err, &invalid) {
+		return invalid
+	}
+	return nil
+}
+
 // Encode converts an integer to a unique string, using the parameters contianed in the IdEncoder
 func (i *IdEncoder) Encode(n, minLength uint64) (encoded string, err error) {
-	return string(i.checksum(n)) + i.enbase(i.scramble(n), minLength), nil
+	b, err := i.EncodeAppend(make([]byte, 0, 16), n, minLength)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// EncodeAppend converts an integer like Encode, appending the result to dst and
+// returning the extended buffer. On error, dst is returned unchanged.
+func (i *IdEncoder) EncodeAppend(dst []byte, n, minLength uint64) ([]byte, error) {
+	if minLength > i.maxLength() {
+		return dst, &IdEncoderError{
+			Message: "Minimum length exceeds maximum length",
+		}
+	}
+	start := len(dst)
+	dst = i.appendBase(append(dst, 0), i.scramble(n^i.Salt), minLength)
+	data := dst[start+1:]
+	if i.FixedWidth && uint64(len(data)) > minLength {
+		return dst[:start], &IdEncoderError{
+			Message: "Encoded value exceeds fixed width",
+		}
+	}
+	if uint64(len(data)) > i.maxLength() {
+		return dst[:start], &IdEncoderError{
+			Message: "Encoded value exceeds maximum length",
+		}
+	}
+	if 1+len(data) > i.maxDecodeLength() {
+		return dst[:start], &IdEncoderError{
+			Message: "Encoded value exceeds maximum decode length",
+		}
+	}
+	dst[start] = i.checksum(n, data)
+	if i.formatted() {
+		dst = append(dst[:start], i.format(string(dst[start:]))...)
+	}
+	if i.Strict {
+		if decoded, err := i.Decode(string(dst[start:])); err != nil || decoded != n {
+			return dst[:start], &IdEncoderError{
+				Message: "Encoded value does not decode to the original value",
+			}
+		}
+	}
+	return dst, nil
+}
+
+// EncodeInt32 converts a signed integer to a unique string using zigzag encoding,
+// for decoding with DecodeInt32
+func (i *IdEncoder) EncodeInt32(n int32, minLength uint64) (encoded string, err error) {
+	return i.Encode(uint64(uint32(n<<1)^uint32(n>>31)), minLength)
 }
 
-// Decode converts an string to an integer, using the parameters contianed in the IdEncoder
+// Decode converts an string to an integer, using the parameters contianed in the IdEncoder.
+// Every configured transform is undone in order: the prefix is stripped, case is
+// folded, separators are removed, the remaining characters are converted from the
+// base of the alphabet and unscrambled, and finally the checksum is verified.
 func (i *IdEncoder) Decode(s string) (decoded uint64, err error) {
-	b := []byte(s)
-	value := i.scramble((i.debase(b[1:])))
-	if i.checksum(value) != b[0] {
-		err = &IdEncoderError{
-			Message: "Error decoding",
+	raw, err := i.parse(s)
+	if err != nil {
+		return 0, err
+	}
+	// reject long input before copying it, so the cost doesn't grow with its length
+	if len(raw) > i.maxDecodeLength() {
+		return 0, ErrTooLong
+	}
+	decoded, err = i.decodeRaw([]byte(raw))
+	if invalid := invalidCharacter(err); invalid != nil {
+		invalid.Index = i.originalIndex(s, invalid.Index)
+	}
+	if err == nil && i.StrictCanonical && !i.isCanonical(s, decoded) {
+		return 0, ErrNotCanonical
+	}
+	return decoded, err
+}
+
+// isCanonical reports whether s is exactly the encoding of n with MinLength
+func (i *IdEncoder) isCanonical(s string, n uint64) bool {
+	// Strict would verify the encoding by decoding it again, recursing back here
+	e := *i
+	e.Strict = false
+	canonical, err := e.Encode(n, i.minLength())
+	return err == nil && canonical == s
+}
+
+// DecodeInto converts b to an integer like Decode, writing the result to out.
+// out is only written if b decodes successfully. Unless Prefix, DecoyPrefixLen,
+// GroupSize, ChecksumPlacement, FoldCase or StrictCanonical are set, DecodeInto
+// does not allocate on success.
+func (i *IdEncoder) DecodeInto(b []byte, out *uint64) error {
+	if i.formatted() || i.FoldCase || i.StrictCanonical {
+		decoded, err := i.Decode(string(b))
+		if err == nil {
+			*out = decoded
 		}
+		return err
+	}
+	decoded, err := i.decodeRaw(b)
+	if err == nil {
+		*out = decoded
+	}
+	return err
+}
+
+// decodeRaw converts a raw encoded value, the checksum followed by the data characters, to an integer
+func (i *IdEncoder) decodeRaw(b []byte) (decoded uint64, err error) {
+	if len(b) < 1 {
+		return 0, ErrTooShort
+	}
+	if len(b) > i.maxDecodeLength() {
+		return 0, ErrTooLong
+	}
+	if i.StrictLength && uint64(len(b)) != 1+i.minLength() {
+		return 0, ErrWrongLength
+	}
+	if i.digit(b[0]) < 0 {
+		return 0, &InvalidCharacterError{Index: 0, Char: b[0]}
+	}
+	debased, err := i.debase(b[1:])
+	if invalid := invalidCharacter(err); invalid != nil {
+		invalid.Index++
+	}
+	if err != nil {
+		return 0, err
+	}
+	value := i.unscramble(debased) ^ i.Salt
+	if i.checksum(value, b[1:]) != b[0] {
+		err = ErrChecksumMismatch
 	}
 	return value, err
 }
 
-func (i *IdEncoder) checksum(n uint64) byte {
-	return i.Alphabet[n%uint64(i.Checksum)]
+// DecodePadding converts a string to an integer like Decode, also reporting whether
+// it carries more leading padding than Encode would produce for the given minLength.
+// Rejecting over-padded input ensures each value has a single valid encoding.
+func (i *IdEncoder) DecodePadding(s string, minLength uint64) (decoded uint64, overPadded bool, err error) {
+	decoded, err = i.Decode(s)
+	if err != nil {
+		return decoded, false, err
+	}
+	raw, _ := i.parse(s)
+	data := []byte(raw)[1:]
+	expected := uint64(len(bytes.TrimLeft(data, i.padding())))
+	// zero is encoded as one zero digit, which trimming removes
+	if expected == 0 {
+		expected = 1
+	}
+	if expected < minLength {
+		expected = minLength
+	}
+	return decoded, uint64(len(data)) > expected, nil
+}
+
+// DecodeStrict converts a string to an integer like Decode, but is intended for untrusted
+// input: in addition to the checks made by Decode (matching checksum, characters in the
+// alphabet, length within MaxDecodeLength), the input must be exactly the canonical
+// encoding of its value with the encoder's MinLength, so each value has one valid form.
+func (i *IdEncoder) DecodeStrict(s string) (uint64, error) {
+	decoded, err := i.Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	canonical, err := i.Encode(decoded, i.minLength())
+	if err != nil {
+		return 0, err
+	}
+	if canonical != s {
+		return 0, ErrNotCanonical
+	}
+	return decoded, nil
+}
+
+// DecodeVerify converts a string to an integer like Decode, but reports a failed
+// checksum through checksumOK rather than as an error, for lenient reads that would
+// rather use a possibly mistyped value than reject it. A checksum character outside
+// the alphabet also just fails the checksum. err is reserved for structural problems
+// such as invalid data characters, a bad length or overflow.
+func (i *IdEncoder) DecodeVerify(s string) (decoded uint64, checksumOK bool, err error) {
+	raw, err := i.parse(s)
+	if err != nil {
+		return 0, false, err
+	}
+	b := []byte(raw)
+	substituted := len(b) > 0 && bytes.IndexByte(i.Alphabet, b[0]) < 0
+	if substituted {
+		b[0] = i.Alphabet[0]
+	}
+	decoded, err = i.decodeRaw(b)
+	if errors.Is(err, ErrChecksumMismatch) {
+		return decoded, false, nil
+	}
+	if invalid := invalidCharacter(err); invalid != nil {
+		invalid.Index = i.originalIndex(s, invalid.Index)
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return decoded, !substituted, nil
+}
+
+// DecodeCanonical converts a string to an integer like Decode, also returning the
+// canonical encoding of the value with the encoder's MinLength, prefix and other
+// options, for example to redirect requests to a single URL per value
+func (i *IdEncoder) DecodeCanonical(s string) (value uint64, canonical string, err error) {
+	value, err = i.Decode(s)
+	if err != nil {
+		return value, "", err
+	}
+	canonical, err = i.Encode(value, i.minLength())
+	if err != nil {
+		return value, "", err
+	}
+	return value, canonical, nil
+}
+
+// DecodeCorrecting converts a string to an integer like Decode, but if the checksum
+// fails, it tries swapping each pair of adjacent characters. If exactly one swap
+// makes s decode, that value is returned with corrected set. Only single adjacent
+// transpositions are tried, so any other typo, or a transposition that several
+// swaps could explain, still returns ErrChecksumMismatch. A corrected value is a
+// likely guess, not a certainty: with a single checksum character, a swap of a
+// code with some other typo passes the checksum about once in Checksum attempts.
+func (i *IdEncoder) DecodeCorrecting(s string) (value uint64, corrected bool, err error) {
+	value, err = i.Decode(s)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		return value, false, err
+	}
+	b := []byte(s)
+	found := false
+	var candidate uint64
+	for k := 0; k+1 < len(b); k++ {
+		if b[k] == b[k+1] {
+			continue
+		}
+		b[k], b[k+1] = b[k+1], b[k]
+		decoded, swapErr := i.Decode(string(b))
+		b[k], b[k+1] = b[k+1], b[k]
+		if swapErr != nil || found && decoded == candidate {
+			continue
+		}
+		if found {
+			return value, false, err
+		}
+		found, candidate = true, decoded
+	}
+	if !found {
+		return value, false, err
+	}
+	return candidate, true, nil
+}
+
+// DecodeOr converts a string to an integer like Decode, but returns fallback
+// instead of an error if the string cannot be decoded
+func (i *IdEncoder) DecodeOr(s string, fallback uint64) uint64 {
+	decoded, err := i.Decode(s)
+	if err != nil {
+		return fallback
+	}
+	return decoded
+}
+
+// DecodeUint32 converts a string to an integer like Decode, returning an error
+// if the decoded value does not fit in a uint32
+func (i *IdEncoder) DecodeUint32(s string) (uint32, error) {
+	decoded, err := i.Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	if decoded > math.MaxUint32 {
+		return 0, fmt.Errorf("%w uint32: %d", ErrOverflow, decoded)
+	}
+	return uint32(decoded), nil
+}
+
+// DecodeInt32 converts a string to a signed integer encoded with zigzag encoding
+// (0, -1, 1, -2, ... map to 0, 1, 2, 3, ...), returning an error if the decoded
+// value does not fit in an int32
+func (i *IdEncoder) DecodeInt32(s string) (int32, error) {
+	decoded, err := i.DecodeUint32(s)
+	if err != nil {
+		return 0, err
+	}
+	return int32(decoded>>1) ^ -int32(decoded&1), nil
+}
+
+// IsValid reports whether s is a well-formed encoded value with a matching checksum.
+// It applies the same checks as Decode, including StrictLength and
+// StrictCanonical, so it is true exactly when Decode succeeds.
+func (i *IdEncoder) IsValid(s string) bool {
+	_, err := i.Decode(s)
+	return err == nil
+}
+
+// Matches reports whether s could be a code from this encoder, for routing tokens
+// between several code schemes. It accepts exactly what IsValid accepts, but first
+// rejects strings of an implausible length or with characters foreign to the
+// alphabet without parsing or converting them.
+func (i *IdEncoder) Matches(s string) bool {
+	if len(s) < 1 {
+		return false
+	}
+	if !i.formatted() && !i.FoldCase {
+		if len(s) > i.maxDecodeLength() {
+			return false
+		}
+		for idx := 0; idx < len(s); idx++ {
+			c := s[idx]
+			if bytes.IndexByte(i.Alphabet, c) < 0 && (c == 0 || c != i.ZeroSymbol && c != i.PadChar) {
+				return false
+			}
+		}
+	}
+	return i.IsValid(s)
+}
+
+// Params returns the radix (alphabet length), the character used for padding and the
+// number of checksum characters, so external validators can reconstruct the
+// expected character set and width
+func (i *IdEncoder) Params() (radix int, padChar byte, checksumLen int) {
+	return len(i.Alphabet), i.padChar(), 1
+}
+
+// padChar returns the configured PadChar, or the first character of the alphabet
+func (i *IdEncoder) padChar() byte {
+	if i.PadChar != 0 {
+		return i.PadChar
+	}
+	return i.Alphabet[0]
+}
+
+// padding returns the characters that may pad the data: the pad character and
+// the first character of the alphabet, which is the digit zero
+func (i *IdEncoder) padding() string {
+	return string([]byte{i.Alphabet[0], i.padChar()})
+}
+
+// checksum returns the check character for the value n, whose encoded data characters are data
+func (i *IdEncoder) checksum(n uint64, data []byte) byte {
+	var index, modulus uint64
+	mode, fn := i.checksumAlgorithm()
+	switch {
+	case fn != nil:
+		modulus = uint64(len(i.Alphabet))
+		index = fn(i, n, data) % modulus
+	case mode == CheckOutput:
+		index, modulus = i.luhn(data), uint64(len(i.Alphabet))
+	case mode == CheckPadded:
+		modulus = i.checksumModulus()
+		index = (n%modulus + uint64(len(data))%modulus) % modulus
+	case mode == CheckScrambled:
+		modulus = i.checksumModulus()
+		index = i.scramble(n^i.Salt) % modulus
+	default:
+		modulus = i.checksumModulus()
+		index = n % modulus
+	}
+	if i.aad != 0 {
+		index = (index + i.aad%modulus) % modulus
+	}
+	return i.Alphabet[index]
+}
+
+// checksumModulus returns Checksum, limited to the alphabet length so that the
+// checksum character is always in the alphabet, even for an encoder that Validate
+// rejects because Checksum is 0 or exceeds the alphabet length
+func (i *IdEncoder) checksumModulus() uint64 {
+	if c := uint64(i.Checksum); c > 0 && c <= uint64(len(i.Alphabet)) {
+		return c
+	}
+	return uint64(len(i.Alphabet))
+}
+
+// luhn computes a Luhn mod N check digit over the alphabet indexes of data
+func (i *IdEncoder) luhn(data []byte) uint64 {
+	n := uint64(len(i.Alphabet))
+	factor, sum := uint64(2), uint64(0)
+	for idx := len(data) - 1; idx >= 0; idx-- {
+		// a ZeroSymbol isn't in the alphabet and counts as the digit zero
+		digit := i.digit(data[idx])
+		if digit < 0 {
+			digit = 0
+		}
+		addend := factor * uint64(digit)
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return (n - sum%n) % n
 }
 
 func (i *IdEncoder) scramble(n uint64) uint64 {
-	mask := uint64((1 << i.BlockSize) - 1)
+	if i.scrambleKey != nil {
+		return i.permute(n)
+	}
+	if i.AlignBlock {
+		return i.scrambleDigits(n)
+	}
+	if i.BitOrder == MSBFirst {
+		return scrambleBytes(n, uint64(i.BlockSize))
+	}
+	return Scramble(n, uint64(i.BlockSize))
+}
+
+// scrambleBytes reverses the bits of each byte within the lower blockSize bits of n,
+// and the bits above the last whole byte among themselves. Like Scramble, it is its
+// own inverse.
+func scrambleBytes(n uint64, blockSize uint64) uint64 {
+	if blockSize > 64 {
+		blockSize = 64
+	}
+	whole := blockSize / 8 * 8
+	result := n
+	for shift := uint64(0); shift < whole; shift += 8 {
+		result = result&^(0xff<<shift) | uint64(bits.Reverse8(uint8(n>>shift)))<<shift
+	}
+	if whole < blockSize {
+		result = result&(1<<whole-1) | Scramble(n>>whole, blockSize-whole)<<whole
+	}
+	return result
+}
+
+// Scramble reverses the lower blockSize bits of n, leaving any higher bits as is.
+// A blockSize larger than 64 is treated as 64. Scramble is its own inverse:
+// Scramble(Scramble(n, b), b) == n for every n and b.
+func Scramble(n uint64, blockSize uint64) uint64 {
+	switch {
+	case blockSize >= 64:
+		return bits.Reverse64(n)
+	case blockSize == 32:
+		return n&^0xffffffff | uint64(bits.Reverse32(uint32(n)))
+	case blockSize == 16:
+		return n&^0xffff | uint64(bits.Reverse16(uint16(n)))
+	case blockSize == 8:
+		return n&^0xff | uint64(bits.Reverse8(uint8(n)))
+	}
+	mask := uint64(1)<<blockSize - 1
 	result := n & ^mask
-	for bit := uint64(0); bit < uint64(i.BlockSize); bit++ {
+	for bit := uint64(0); bit < blockSize; bit++ {
 		if n&(1<<bit) != 0 {
-			result |= 1 << (uint64(i.BlockSize) - bit - 1)
+			result |= 1 << (blockSize - bit - 1)
 		}
 	}
 	return result
 }
 
-func (i *IdEncoder) enbase(x, minLength uint64) string {
-	n := uint64(len(i.Alphabet))
-	chars := []byte{}
-	for x > 0 {
-		c := x % n
-		x = uint64(x / n)
-		chars = append([]byte{i.Alphabet[c]}, chars...)
+// unscramble inverts scramble
+func (i *IdEncoder) unscramble(n uint64) uint64 {
+	if i.scrambleKey != nil {
+		return i.unpermute(n)
 	}
-	return leftPad(string(chars), minLength, i.Alphabet[0])
+	return i.scramble(n)
 }
 
-func (i *IdEncoder) debase(x []byte) uint64 {
+// scrambleDigits reverses the lowest base-N digits of n covering BlockSize bits
+func (i *IdEncoder) scrambleDigits(n uint64) uint64 {
+	base := uint64(len(i.Alphabet))
+	if base < 2 || i.BlockSize == 0 {
+		return n
+	}
+	span := i.digitSpan()
+	high, low := n/span, n%span
+	if high > (math.MaxUint64-(span-1))/span {
+		// the topmost partial block can't be shuffled without overflow
+		return n
+	}
 	result := uint64(0)
-	n := uint64(len(i.Alphabet))
-	for _, val := range x {
-		result *= n
-		result += uint64(bytes.IndexByte(i.Alphabet, val))
+	for d := uint64(1); d < span; d *= base {
+		result = result*base + low%base
+		low /= base
 	}
-	return result
+	return high*span + result
+}
+
+// digitSpan returns base^k for the smallest number of digits k covering BlockSize bits,
+// limited to the largest power of the base that fits in a uint64
+func (i *IdEncoder) digitSpan() uint64 {
+	base := uint64(len(i.Alphabet))
+	span := uint64(1)
+	for covered := 0; covered < int(i.BlockSize); {
+		if span > math.MaxUint64/base {
+			break
+		}
+		span *= base
+		covered = bits.Len64(span - 1)
+	}
+	return span
+}
+
+// RecommendedBlockSize returns the smallest block size that shuffles every bit of
+// maxValue, so no values up to maxValue leave predictable high bits unshuffled
+func RecommendedBlockSize(maxValue uint64) uint64 {
+	return uint64(bits.Len64(maxValue))
+}
+
+// minLength returns the configured MinLength, or the package MinLength
+func (i *IdEncoder) minLength() uint64 {
+	if i.MinLength > 0 {
+		return i.MinLength
+	}
+	return MinLength
+}
+
+// maxLength returns the configured MaxLength, or DefaultMaxLength
+func (i *IdEncoder) maxLength() uint64 {
+	if i.MaxLength > 0 {
+		return uint64(i.MaxLength)
+	}
+	return DefaultMaxLength
+}
+
+// maxDecodeLength returns the configured MaxDecodeLength, or a default long enough
+// for any value Encode can produce: a checksum plus the longer of maxDigits and
+// maxLength data characters
+func (i *IdEncoder) maxDecodeLength() int {
+	if i.MaxDecodeLength > 0 {
+		return i.MaxDecodeLength
+	}
+	if digits := i.maxDigits(); uint64(digits) > i.maxLength() {
+		return 1 + digits
+	}
+	return 1 + int(i.maxLength())
 }
 
-func times(c byte, n uint64) []byte {
-	chars := make([]byte, n)
-	for i := uint64(0); i < n; i++ {
-		chars[i] = c
+// maxDigits returns the number of alphabet characters needed to represent math.MaxUint64
+func (i *IdEncoder) maxDigits() int {
+	digits := 0
+	for x := uint64(math.MaxUint64); x > 0; x /= uint64(len(i.Alphabet)) {
+		digits++
 	}
-	return chars
+	return digits
+}
+
+func (i *IdEncoder) enbase(x, minLength uint64) string {
+	return string(i.appendBase(nil, x, minLength))
 }
 
-func leftPad(str string, length uint64, pad byte) string {
-	return fmt.Sprintf("%v%v", string(times(pad, length-uint64(len(str)))), str)
+// appendBase appends x converted to the base of the alphabet to dst, left padded
+// with the pad character to at least minLength characters
+func (i *IdEncoder) appendBase(dst []byte, x, minLength uint64) []byte {
+	n := uint64(len(i.Alphabet))
+	digits := uint64(0)
+	for y := x; y > 0; y /= n {
+		digits++
+	}
+	// ZeroSymbol needs a digit to replace
+	if x == 0 && i.ZeroSymbol != 0 {
+		digits = 1
+	}
+	pad := i.padChar()
+	for k := digits; k < minLength; k++ {
+		dst = append(dst, pad)
+	}
+	for k := uint64(0); k < digits; k++ {
+		dst = append(dst, i.Alphabet[0])
+	}
+	if x == 0 && i.ZeroSymbol != 0 {
+		dst[len(dst)-1] = i.ZeroSymbol
+	}
+	for k := len(dst) - 1; x > 0; k-- {
+		dst[k] = i.Alphabet[x%n]
+		x /= n
+	}
+	return dst
+}
+
+// debase converts x from the base of the alphabet, after any leading PadChar that
+// isn't in the alphabet, reporting the index within x of the first character that
+// is not in the alphabet, or ErrOverflow if x represents a value larger than
+// math.MaxUint64
+func (i *IdEncoder) debase(x []byte) (uint64, error) {
+	result := uint64(0)
+	n := uint64(len(i.Alphabet))
+	padded := i.PadChar != 0 && bytes.IndexByte(i.Alphabet, i.PadChar) < 0
+	for idx, val := range x {
+		if padded {
+			if val == i.PadChar {
+				continue
+			}
+			padded = false
+		}
+		digit := i.digit(val)
+		if i.ZeroSymbol != 0 && val == i.ZeroSymbol && idx == len(x)-1 && result == 0 {
+			return 0, nil
+		}
+		if digit < 0 {
+			return 0, &InvalidCharacterError{Index: idx, Char: val}
+		}
+		if result > (math.MaxUint64-uint64(digit))/n {
+			return 0, ErrOverflow
+		}
+		result *= n
+		result += uint64(digit)
+	}
+	if i.ZeroSymbol != 0 && result == 0 {
+		return 0, ErrNotCanonical
+	}
+	return result, nil
 }
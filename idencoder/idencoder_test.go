@@ -0,0 +1,509 @@
+package idencoder
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testEncoder returns an encoder with the default settings
+func testEncoder() *IdEncoder {
+	return &IdEncoder{
+		Alphabet:  Alphabet(DefaultAlphabet),
+		BlockSize: DefaultBlockSize,
+		Checksum:  DefaultChecksum,
+	}
+}
+
+func TestRoundTripBeyondMaxDigits(t *testing.T) {
+	i := testEncoder()
+	for _, minLength := range []uint64{uint64(i.maxDigits()) + 1, 20, DefaultMaxLength} {
+		for _, n := range []uint64{0, 5, 1 << 40, 1<<64 - 1} {
+			encoded, err := i.Encode(n, minLength)
+			if err != nil {
+				t.Fatalf("Encode(%d, %d): %v", n, minLength, err)
+			}
+			if uint64(len(encoded)) != 1+minLength {
+				t.Errorf("Encode(%d, %d) = %q, want %d characters", n, minLength, encoded, 1+minLength)
+			}
+			if decoded, err := i.Decode(encoded); err != nil || decoded != n {
+				t.Errorf("Decode(%q) = %d, %v, want %d", encoded, decoded, err, n)
+			}
+		}
+	}
+}
+
+func TestEncodeRefusesUndecodableLength(t *testing.T) {
+	i := testEncoder()
+	i.MaxDecodeLength = 10
+	if encoded, err := i.Encode(5, 10); err == nil {
+		t.Errorf("Encode(5, 10) = %q, want an error as Decode accepts at most 10 characters", encoded)
+	}
+	if _, err := i.Encode(5, 9); err != nil {
+		t.Errorf("Encode(5, 9): %v", err)
+	}
+}
+
+func TestDecodePadding(t *testing.T) {
+	i := testEncoder()
+	tests := []struct {
+		n, encodeLength, decodeLength uint64
+		overPadded                    bool
+	}{
+		{0, 0, 0, false},
+		{0, 1, 0, false},
+		{0, 2, 0, true},
+		{0, MinLength, MinLength, false},
+		{0, MinLength + 1, MinLength, true},
+		{123456789, 0, 0, false},
+		{123456789, MinLength, MinLength, false},
+		{123456789, 12, 12, false},
+		{123456789, 12, MinLength, true},
+	}
+	for _, tt := range tests {
+		encoded, err := i.Encode(tt.n, tt.encodeLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, overPadded, err := i.DecodePadding(encoded, tt.decodeLength)
+		if err != nil || decoded != tt.n || overPadded != tt.overPadded {
+			t.Errorf("DecodePadding(%q, %d) = %d, %v, %v, want %d, %v",
+				encoded, tt.decodeLength, decoded, overPadded, err, tt.n, tt.overPadded)
+		}
+	}
+}
+
+func TestAlignBlockKeepsHighDigits(t *testing.T) {
+	i := testEncoder()
+	i.AlignBlock = true
+	span := i.digitSpan()
+	blockDigits := 0
+	for x := span; x > 1; x /= uint64(len(i.Alphabet)) {
+		blockDigits++
+	}
+	const width = 10
+	unaligned := testEncoder()
+	unalignedPrefixes := make(map[string]bool)
+	for _, high := range []uint64{1, 2, 12345} {
+		var prefix string
+		for _, low := range []uint64{0, 1, 7, span / 2, span - 1} {
+			n := high*span + low
+			encoded, err := i.Encode(n, width)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded, err := i.Decode(encoded); err != nil || decoded != n {
+				t.Fatalf("Decode(%q) = %d, %v, want %d", encoded, decoded, err, n)
+			}
+			// skip the checksum, then compare the characters above the block
+			got := encoded[1 : len(encoded)-blockDigits]
+			if prefix == "" {
+				prefix = got
+			} else if got != prefix {
+				t.Errorf("%d encodes to %q, whose leading characters %q differ from %q", n, encoded, got, prefix)
+			}
+			u, err := unaligned.Encode(n, width)
+			if err != nil {
+				t.Fatal(err)
+			}
+			unalignedPrefixes[u[1:len(u)-blockDigits]] = true
+		}
+	}
+	// without alignment, carries from the reversed bits spill into the leading characters
+	if len(unalignedPrefixes) <= 3 {
+		t.Errorf("unaligned encodings share %d leading character runs, want more than one per high value", len(unalignedPrefixes))
+	}
+}
+
+// testValues are values around bit boundaries and a spread between them
+func testValues() []uint64 {
+	values := []uint64{0, 1, 2, 3, 1<<64 - 1, 1<<64 - 2, 0xdeadbeefcafebabe}
+	for bit := uint(1); bit < 64; bit++ {
+		values = append(values, 1<<bit-1, 1<<bit, 1<<bit+1)
+	}
+	for n := uint64(12345); n < 1<<62; n *= 7 {
+		values = append(values, n)
+	}
+	return values
+}
+
+func TestScrambleIsItsOwnInverse(t *testing.T) {
+	for b := uint64(0); b <= 70; b++ {
+		for _, n := range testValues() {
+			if got := Scramble(Scramble(n, b), b); got != n {
+				t.Errorf("Scramble(Scramble(%d, %d), %d) = %d", n, b, b, got)
+			}
+		}
+	}
+}
+
+// naiveScramble reverses the lower blockSize bits of n one bit at a time
+func naiveScramble(n uint64, blockSize uint64) uint64 {
+	if blockSize > 64 {
+		blockSize = 64
+	}
+	result := n
+	for bit := uint64(0); bit < blockSize; bit++ {
+		result &^= 1 << bit
+		result |= (n >> (blockSize - 1 - bit) & 1) << bit
+	}
+	return result
+}
+
+func TestScrambleMatchesNaive(t *testing.T) {
+	for b := uint64(0); b <= 70; b++ {
+		for _, n := range testValues() {
+			if got, want := Scramble(n, b), naiveScramble(n, b); got != want {
+				t.Errorf("Scramble(%#x, %d) = %#x, want %#x", n, b, got, want)
+			}
+		}
+	}
+	if got := Scramble(1, 64); got != 1<<63 {
+		t.Errorf("Scramble(1, 64) = %#x, want bit 0 moved to bit 63", got)
+	}
+}
+
+func TestScrambleFastPaths(t *testing.T) {
+	for _, b := range []uint64{8, 16, 32, 64} {
+		for k := uint64(0); k < 10000; k++ {
+			n := k * 0x9e3779b97f4a7c15
+			if got, want := Scramble(n, b), naiveScramble(n, b); got != want {
+				t.Errorf("Scramble(%#x, %d) = %#x, want %#x", n, b, got, want)
+			}
+		}
+	}
+}
+
+var scrambleSink uint64
+
+func BenchmarkScramble32(b *testing.B) {
+	b.Run("bits", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			scrambleSink = Scramble(uint64(n), 32)
+		}
+	})
+	b.Run("loop", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			scrambleSink = naiveScramble(uint64(n), 32)
+		}
+	})
+}
+
+func TestSalt(t *testing.T) {
+	a, b := testEncoder(), testEncoder()
+	a.Salt, b.Salt = 1, 0x9e3779b97f4a7c15
+	plain := testEncoder()
+	for _, n := range testValues() {
+		codeA, errA := a.Encode(n, MinLength)
+		codeB, errB := b.Encode(n, MinLength)
+		if errA != nil || errB != nil {
+			t.Fatal(errA, errB)
+		}
+		if codeA == codeB {
+			t.Errorf("%d encodes to %q with both salts", n, codeA)
+		}
+		for _, c := range []struct {
+			i    *IdEncoder
+			code string
+		}{{a, codeA}, {b, codeB}} {
+			if decoded, err := c.i.Decode(c.code); err != nil || decoded != n {
+				t.Errorf("Decode(%q) = %d, %v, want %d", c.code, decoded, err, n)
+			}
+		}
+		if decoded, err := plain.Decode(codeA); err == nil && decoded == n {
+			t.Errorf("%q decodes to %d without the salt", codeA, n)
+		}
+	}
+}
+
+func TestDecode32(t *testing.T) {
+	i := testEncoder()
+	for _, tt := range []struct {
+		n  uint64
+		ok bool
+	}{
+		{0, true},
+		{math.MaxUint32 - 1, true},
+		{math.MaxUint32, true},
+		{math.MaxUint32 + 1, false},
+		{math.MaxUint64, false},
+	} {
+		encoded, err := i.Encode(tt.n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := i.DecodeUint32(encoded)
+		if tt.ok && (err != nil || uint64(decoded) != tt.n) {
+			t.Errorf("DecodeUint32(%q) = %d, %v, want %d", encoded, decoded, err, tt.n)
+		}
+		if !tt.ok && !errors.Is(err, ErrOverflow) {
+			t.Errorf("DecodeUint32(%q) = %d, %v, want ErrOverflow", encoded, decoded, err)
+		}
+	}
+	for _, n := range []int32{0, -1, 1, math.MinInt32, math.MaxInt32} {
+		encoded, err := i.EncodeInt32(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := i.DecodeInt32(encoded); err != nil || decoded != n {
+			t.Errorf("DecodeInt32(%q) = %d, %v, want %d", encoded, decoded, err, n)
+		}
+	}
+	beyond, _ := i.Encode(math.MaxUint32+1, MinLength)
+	if _, err := i.DecodeInt32(beyond); !errors.Is(err, ErrOverflow) {
+		t.Errorf("DecodeInt32(%q) error %v, want ErrOverflow", beyond, err)
+	}
+}
+
+func TestStrictRejectsBrokenConfig(t *testing.T) {
+	// the last character repeats the first, so the digit 30 decodes as 0
+	broken := Alphabet(DefaultAlphabet[:30] + DefaultAlphabet[:1])
+	lenient := testEncoder()
+	lenient.Alphabet = broken
+	strict := testEncoder()
+	strict.Alphabet = broken
+	strict.Strict = true
+	caught := 0
+	for n := uint64(0); n < 1000; n++ {
+		code, err := lenient.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, decodeErr := lenient.Decode(code)
+		roundTrips := decodeErr == nil && decoded == n
+		strictCode, err := strict.Encode(n, MinLength)
+		if roundTrips && (err != nil || strictCode != code) {
+			t.Errorf("strict Encode(%d) = %q, %v, want %q", n, strictCode, err, code)
+		}
+		if !roundTrips {
+			if err == nil {
+				t.Errorf("strict Encode(%d) = %q, which doesn't decode back", n, strictCode)
+			}
+			caught++
+		}
+	}
+	if caught == 0 {
+		t.Fatal("the broken alphabet never failed to round-trip")
+	}
+}
+
+func TestRecommendedBlockSize(t *testing.T) {
+	tests := []struct {
+		maxValue, want uint64
+	}{
+		{0, 0},
+		{1, 1},
+		{255, 8},
+		{256, 9},
+		{1<<32 - 1, 32},
+		{math.MaxUint64, 64},
+	}
+	for _, tt := range tests {
+		got := RecommendedBlockSize(tt.maxValue)
+		if got != tt.want {
+			t.Errorf("RecommendedBlockSize(%d) = %d, want %d", tt.maxValue, got, tt.want)
+		}
+		// every value up to maxValue fits in the block, so no bits escape the shuffle
+		if Scramble(tt.maxValue, got)>>got != 0 && got < 64 {
+			t.Errorf("RecommendedBlockSize(%d) = %d leaves high bits unshuffled", tt.maxValue, got)
+		}
+	}
+}
+
+func TestStrictLength(t *testing.T) {
+	i := testEncoder()
+	i.MinLength = 8
+	i.StrictLength = true
+	for _, n := range []uint64{0, 1, 123456789} {
+		exact, err := i.Encode(n, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := i.Decode(exact); err != nil || decoded != n {
+			t.Errorf("Decode(%q) = %d, %v, want %d", exact, decoded, err, n)
+		}
+		for _, minLength := range []uint64{7, 9} {
+			code, err := i.Encode(n, minLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(code) == len(exact) {
+				continue
+			}
+			if _, err := i.Decode(code); !errors.Is(err, ErrWrongLength) {
+				t.Errorf("Decode(%q) error %v, want ErrWrongLength", code, err)
+			}
+		}
+	}
+}
+
+func TestDecodeUndoesEveryOption(t *testing.T) {
+	i := testEncoder()
+	i.Prefix = "id_"
+	i.DecoyPrefixLen = 2
+	i.GroupSize = 3
+	i.ChecksumPlacement = ChecksumTrailing
+	i.FoldCase = true
+	i.Salt = 0x5eed
+	i.PadChar = '.'
+	i.MinLength = 10
+	for _, n := range testValues() {
+		encoded, err := i.Encode(n, i.MinLength)
+		if err != nil {
+			t.Fatalf("Encode(%d): %v", n, err)
+		}
+		for _, s := range []string{encoded, i.Prefix + strings.ToUpper(encoded[len(i.Prefix):])} {
+			if decoded, err := i.Decode(s); err != nil || decoded != n {
+				t.Errorf("Decode(%q) = %d, %v, want %d", s, decoded, err, n)
+			}
+		}
+	}
+}
+
+func TestFixedWidth(t *testing.T) {
+	i := testEncoder()
+	i.BlockSize = 0
+	i.FixedWidth = true
+	// three base-31 digits
+	largest := uint64(len(i.Alphabet)*len(i.Alphabet)*len(i.Alphabet) - 1)
+	for _, n := range []uint64{0, largest} {
+		encoded, err := i.Encode(n, 3)
+		if err != nil || len(encoded) != 4 {
+			t.Errorf("Encode(%d, 3) = %q, %v, want 4 characters", n, encoded, err)
+		}
+	}
+	if encoded, err := i.Encode(largest+1, 3); err == nil {
+		t.Errorf("Encode(%d, 3) = %q, want an error", largest+1, encoded)
+	}
+	i.FixedWidth = false
+	if encoded, err := i.Encode(largest+1, 3); err != nil || len(encoded) != 5 {
+		t.Errorf("Encode(%d, 3) without FixedWidth = %q, %v, want 5 characters", largest+1, encoded, err)
+	}
+}
+
+func TestBitOrder(t *testing.T) {
+	lsb, msb := testEncoder(), testEncoder()
+	lsb.BitOrder, msb.BitOrder = LSBFirst, MSBFirst
+	if testEncoder().BitOrder != LSBFirst {
+		t.Error("LSBFirst isn't the default BitOrder")
+	}
+	differ := 0
+	values := testValues()
+	for _, n := range values {
+		for _, i := range []*IdEncoder{lsb, msb} {
+			code, err := i.Encode(n, MinLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded, err := i.Decode(code); err != nil || decoded != n {
+				t.Errorf("BitOrder %d: Decode(%q) = %d, %v, want %d", i.BitOrder, code, decoded, err, n)
+			}
+		}
+		a, _ := lsb.Encode(n, MinLength)
+		b, _ := msb.Encode(n, MinLength)
+		if a != b {
+			differ++
+		}
+	}
+	if differ < len(values)/2 {
+		t.Errorf("LSBFirst and MSBFirst encode %d of %d values differently, want most", differ, len(values))
+	}
+}
+
+func TestZero(t *testing.T) {
+	plain := testEncoder()
+	zero := testEncoder()
+	zero.ZeroSymbol = '_'
+	pad := string(plain.Alphabet[0])
+	for _, minLength := range []uint64{0, 1, 5, 12} {
+		// zero has no digits, but ZeroSymbol takes one
+		width := int(minLength)
+		code, err := plain.Encode(0, minLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.Repeat(pad, width); code[1:] != want {
+			t.Errorf("Encode(0, %d) = %q, want data %q", minLength, code, want)
+		}
+		if decoded, err := plain.Decode(code); err != nil || decoded != 0 {
+			t.Errorf("Decode(%q) = %d, %v, want 0", code, decoded, err)
+		}
+		if width < 1 {
+			width = 1
+		}
+		code, err = zero.Encode(0, minLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.Repeat(pad, width-1) + "_"; code[1:] != want {
+			t.Errorf("Encode(0, %d) with ZeroSymbol = %q, want data %q", minLength, code, want)
+		}
+		if decoded, err := zero.Decode(code); err != nil || decoded != 0 {
+			t.Errorf("Decode(%q) = %d, %v, want 0", code, decoded, err)
+		}
+	}
+	checksum := plain.checksum(0, []byte(pad))
+	for width := 1; width <= 12; width++ {
+		code := string(checksum) + strings.Repeat(pad, width)
+		if decoded, err := plain.Decode(code); err != nil || decoded != 0 {
+			t.Errorf("Decode(%q) = %d, %v, want 0", code, decoded, err)
+		}
+		if _, err := zero.Decode(code); !errors.Is(err, ErrNotCanonical) {
+			t.Errorf("Decode(%q) with ZeroSymbol error %v, want ErrNotCanonical", code, err)
+		}
+	}
+}
+
+func TestPadChar(t *testing.T) {
+	i := testEncoder()
+	i.PadChar = '.'
+	if err := i.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	plain := testEncoder()
+	for _, n := range testValues() {
+		code, err := i.Encode(n, 16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := plain.Encode(n, 0)
+		if want := raw[:1] + strings.Repeat(".", 17-len(raw)) + raw[1:]; code != want {
+			t.Errorf("Encode(%d, 16) = %q, want %q", n, code, want)
+		}
+		if decoded, err := i.Decode(code); err != nil || decoded != n {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+		}
+	}
+	i.PadChar = i.Alphabet[0]
+	if err := i.Validate(); err != nil {
+		t.Errorf("PadChar as the first alphabet character: %v", err)
+	}
+	for _, c := range []byte{i.Alphabet[1], DefaultSeparator} {
+		i.PadChar = c
+		i.GroupSize = 3
+		if err := i.Validate(); err == nil {
+			t.Errorf("Validate accepted PadChar %q", c)
+		}
+	}
+}
+
+func TestDecodeOr(t *testing.T) {
+	i := testEncoder()
+	const fallback = 42
+	tests := []struct {
+		name string
+		s    string
+		want uint64
+	}{
+		{"valid", "nf9zwf", 1000}, // see TestGoldenCodes
+		{"bad character", "nf9!wf", fallback},
+		{"bad checksum", "ff9zwf", fallback},
+		{"empty", "", fallback},
+	}
+	for _, tt := range tests {
+		if got := i.DecodeOr(tt.s, fallback); got != tt.want {
+			t.Errorf("%s: DecodeOr(%q, %d) = %d, want %d", tt.name, tt.s, fallback, got, tt.want)
+		}
+	}
+}
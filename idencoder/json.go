@@ -0,0 +1,97 @@
+package idencoder
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// EncodeRequest is the wire format of a batch encode request
+type EncodeRequest struct {
+	Values    []uint64 `json:"values"`
+	MinLength uint64   `json:"minLength,omitempty"`
+}
+
+// EncodeResponse is the wire format of a batch encode response
+type EncodeResponse struct {
+	Codes []string `json:"codes"`
+}
+
+// DecodeRequest is the wire format of a batch decode request
+type DecodeRequest struct {
+	Codes []string `json:"codes"`
+}
+
+// DecodeResponse is the wire format of a batch decode response
+type DecodeResponse struct {
+	Values []uint64 `json:"values"`
+}
+
+// ErrorResponse is the wire format of a failed batch request
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Index *int   `json:"index,omitempty"`
+}
+
+// HandleEncodeJSON reads an EncodeRequest from r and writes an EncodeResponse to w.
+// If the request is invalid, an ErrorResponse is written instead and the error is returned.
+// A MinLength of 0 uses the encoder's MinLength.
+func (i *IdEncoder) HandleEncodeJSON(r io.Reader, w io.Writer) error {
+	var req EncodeRequest
+	if err := decodeJSONRequest(r, &req); err != nil {
+		return writeJSONError(w, err, nil)
+	}
+	if len(req.Values) == 0 {
+		return writeJSONError(w, &IdEncoderError{Message: "No values to encode"}, nil)
+	}
+	minLength := req.MinLength
+	if minLength == 0 {
+		minLength = i.minLength()
+	}
+	resp := EncodeResponse{Codes: make([]string, len(req.Values))}
+	for idx, value := range req.Values {
+		encoded, err := i.Encode(value, minLength)
+		if err != nil {
+			return writeJSONError(w, err, &idx)
+		}
+		resp.Codes[idx] = encoded
+	}
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// HandleDecodeJSON reads a DecodeRequest from r and writes a DecodeResponse to w.
+// If the request is invalid, an ErrorResponse is written instead and the error is returned.
+func (i *IdEncoder) HandleDecodeJSON(r io.Reader, w io.Writer) error {
+	var req DecodeRequest
+	if err := decodeJSONRequest(r, &req); err != nil {
+		return writeJSONError(w, err, nil)
+	}
+	if len(req.Codes) == 0 {
+		return writeJSONError(w, &IdEncoderError{Message: "No codes to decode"}, nil)
+	}
+	resp := DecodeResponse{Values: make([]uint64, len(req.Codes))}
+	for idx, code := range req.Codes {
+		decoded, err := i.Decode(code)
+		if err != nil {
+			return writeJSONError(w, err, &idx)
+		}
+		resp.Values[idx] = decoded
+	}
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func decodeJSONRequest(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &IdEncoderError{Message: "Invalid JSON request: " + err.Error()}
+	}
+	return nil
+}
+
+// writeJSONError writes err to w as an ErrorResponse and returns err
+func writeJSONError(w io.Writer, err error, index *int) error {
+	if writeErr := json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error(), Index: index}); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package idencoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHandleJSONRoundTrip(t *testing.T) {
+	i := testEncoder()
+	var encoded bytes.Buffer
+	if err := i.HandleEncodeJSON(strings.NewReader(`{"values":[0,1,123456789],"minLength":6}`), &encoded); err != nil {
+		t.Fatal(err)
+	}
+	var encodeResp EncodeResponse
+	if err := json.Unmarshal(encoded.Bytes(), &encodeResp); err != nil {
+		t.Fatal(err)
+	}
+	if len(encodeResp.Codes) != 3 {
+		t.Fatalf("got %d codes, want 3", len(encodeResp.Codes))
+	}
+	for _, code := range encodeResp.Codes {
+		if len(code) != 7 {
+			t.Errorf("code %q isn't padded to a minLength of 6", code)
+		}
+	}
+	req, _ := json.Marshal(DecodeRequest{Codes: encodeResp.Codes})
+	var decoded bytes.Buffer
+	if err := i.HandleDecodeJSON(bytes.NewReader(req), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var decodeResp DecodeResponse
+	if err := json.Unmarshal(decoded.Bytes(), &decodeResp); err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeResp.Values; len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 123456789 {
+		t.Errorf("decoded %v, want [0 1 123456789]", got)
+	}
+}
+
+func TestHandleJSONErrors(t *testing.T) {
+	i := testEncoder()
+	valid, _ := i.Encode(5, MinLength)
+	tests := []struct {
+		name   string
+		handle func(*IdEncoder, *strings.Reader, *bytes.Buffer) error
+		body   string
+		index  *int
+	}{
+		{"malformed", encodeJSON, `{"values":`, nil},
+		{"unknown field", encodeJSON, `{"values":[1],"extra":true}`, nil},
+		{"no values", encodeJSON, `{"values":[]}`, nil},
+		{"no codes", decodeJSON, `{"codes":[]}`, nil},
+		{"bad code", decodeJSON, `{"codes":["` + valid + `","!!!!!!"]}`, intPtr(1)},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := tt.handle(i, strings.NewReader(tt.body), &out); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+		var resp ErrorResponse
+		if err := json.Unmarshal(out.Bytes(), &resp); err != nil || resp.Error == "" {
+			t.Errorf("%s: response %q isn't an ErrorResponse", tt.name, out.String())
+			continue
+		}
+		if (resp.Index == nil) != (tt.index == nil) || resp.Index != nil && *resp.Index != *tt.index {
+			t.Errorf("%s: index %v, want %v", tt.name, resp.Index, tt.index)
+		}
+	}
+}
+
+func encodeJSON(i *IdEncoder, r *strings.Reader, w *bytes.Buffer) error {
+	return i.HandleEncodeJSON(r, w)
+}
+
+func decodeJSON(i *IdEncoder, r *strings.Reader, w *bytes.Buffer) error {
+	return i.HandleDecodeJSON(r, w)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestHandleEncodeJSONUsesMinLength(t *testing.T) {
+	i := strictLengthEncoder()
+	var out bytes.Buffer
+	if err := i.HandleEncodeJSON(strings.NewReader(`{"values":[0,7]}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	var resp EncodeResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Codes) != 2 {
+		t.Fatalf("got %d codes, want 2", len(resp.Codes))
+	}
+	for idx, code := range resp.Codes {
+		if decoded, err := i.Decode(code); err != nil || decoded != []uint64{0, 7}[idx] {
+			t.Errorf("Decode(%q) = %d, %v", code, decoded, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package idencoder
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// feistelRounds is the number of rounds of the keyed permutation
+const feistelRounds = 8
+
+// SetScrambleKey replaces the bit-reversal scramble with a keyed permutation of the
+// full 64-bit value space. Values are run through a balanced Feistel network whose
+// round function is AES, keyed by the SHA-256 of key, so consecutive values produce
+// unpredictable codes without the key while remaining bijective and deterministic.
+// BlockSize and AlignBlock are ignored while a key is set. An empty key restores
+// the default bit-reversal scramble.
+func (i *IdEncoder) SetScrambleKey(key []byte) {
+	if len(key) == 0 {
+		i.scrambleKey = nil
+		return
+	}
+	sum := sha256.Sum256(key)
+	block, _ := aes.NewCipher(sum[:]) // a 32 byte key is always valid
+	i.scrambleKey = block
+}
+
+// permute runs n forward through the keyed Feistel network
+func (i *IdEncoder) permute(n uint64) uint64 {
+	left, right := uint32(n>>32), uint32(n)
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^i.feistel(round, right)
+	}
+	return uint64(left)<<32 | uint64(right)
+}
+
+// unpermute runs n backward through the keyed Feistel network, inverting permute
+func (i *IdEncoder) unpermute(n uint64) uint64 {
+	left, right := uint32(n>>32), uint32(n)
+	for round := feistelRounds - 1; round >= 0; round-- {
+		left, right = right^i.feistel(round, left), left
+	}
+	return uint64(left)<<32 | uint64(right)
+}
+
+// feistel is the round function of the keyed permutation
+func (i *IdEncoder) feistel(round int, half uint32) uint32 {
+	var in, out [aes.BlockSize]byte
+	in[0] = byte(round)
+	binary.BigEndian.PutUint32(in[1:], half)
+	i.scrambleKey.Encrypt(out[:], in[:])
+	return binary.BigEndian.Uint32(out[:])
+}
+
+// Rotate returns a copy of the encoder, including its scramble key, that uses a
+// different alphabet of the same length. Only the presentation of codes changes:
+// each value maps to the same sequence of alphabet positions. Codes issued by the
+// original encoder do not decode with the copy; they must be migrated, with
+// Transcode or MigrateCodes, or decoded by the original encoder, for example
+// through an EncoderPool. The copy doesn't fall back to the old alphabet itself
+// because the alphabets share their characters, so about one old code in
+// Checksum would decode to the wrong value rather than fail. Combined with
+// SetScrambleKey, this keeps the secret in the key, leaving the alphabet free to
+// be rotated for presentation reasons.
+func (i *IdEncoder) Rotate(alphabet Alphabet) (*IdEncoder, error) {
+	if len(alphabet) != len(i.Alphabet) {
+		return nil, &IdEncoderError{
+			Message: "Alphabets differ in length",
+		}
+	}
+	rotated := *i
+	rotated.Alphabet = alphabet
+	if rotated.lookup != nil {
+		rotated.lookup = newByteTable(alphabet)
+	}
+	if err := rotated.Validate(); err != nil {
+		return nil, err
+	}
+	return &rotated, nil
+}
+
+// MigrateCodes encodes each of ids with both encoders, returning a map from each
+// code issued by from to the code for the same value under to, so references held
+// elsewhere can be updated in bulk when rotating to a new alphabet
+func MigrateCodes(from, to *IdEncoder, ids []uint64, minLength uint64) (map[string]string, error) {
+	codes := make(map[string]string, len(ids))
+	for _, id := range ids {
+		oldCode, err := from.Encode(id, minLength)
+		if err != nil {
+			return nil, err
+		}
+		newCode, err := to.Encode(id, minLength)
+		if err != nil {
+			return nil, err
+		}
+		codes[oldCode] = newCode
+	}
+	return codes, nil
+}
@@ -0,0 +1,152 @@
+package idencoder
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// keyedEncoder returns a default encoder with a scramble key
+func keyedEncoder(key string) *IdEncoder {
+	i := testEncoder()
+	i.SetScrambleKey([]byte(key))
+	return i
+}
+
+func TestScrambleKeyRoundTripsWithoutCollisions(t *testing.T) {
+	i := keyedEncoder("secret")
+	other := keyedEncoder("other secret")
+	samples := 100000
+	if testing.Short() {
+		samples = 10000
+	}
+	values := make([]uint64, 0, 2*samples)
+	for n := uint64(0); n < uint64(samples); n++ {
+		values = append(values, n)
+	}
+	r := rand.New(rand.NewSource(1))
+	for len(values) < cap(values) {
+		values = append(values, r.Uint64())
+	}
+	seen := make(map[string]uint64, len(values))
+	differs := 0
+	for _, n := range values {
+		code, err := i.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := i.Decode(code); err != nil || decoded != n {
+			t.Fatalf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+		}
+		if prev, ok := seen[code]; ok && prev != n {
+			t.Fatalf("%d and %d both encode to %q", prev, n, code)
+		}
+		seen[code] = n
+		if otherCode, _ := other.Encode(n, MinLength); otherCode != code {
+			differs++
+		}
+	}
+	if differs < len(values)*9/10 {
+		t.Errorf("only %d of %d values encode differently under another key", differs, len(values))
+	}
+}
+
+func TestPermuteIsInvertible(t *testing.T) {
+	i := keyedEncoder("secret")
+	for _, n := range testValues() {
+		if got := i.unpermute(i.permute(n)); got != n {
+			t.Errorf("unpermute(permute(%d)) = %d", n, got)
+		}
+	}
+	i.SetScrambleKey(nil)
+	if got := i.scramble(12345); got != Scramble(12345, DefaultBlockSize) {
+		t.Errorf("an empty key doesn't restore the bit-reversal scramble")
+	}
+}
+
+func TestRotateKeepsKey(t *testing.T) {
+	i := keyedEncoder("secret")
+	rotated, err := i.Rotate(i.Alphabet.Shuffle(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rotated.Alphabet) == string(i.Alphabet) {
+		t.Fatal("Shuffle(7) left the alphabet unchanged")
+	}
+	for _, n := range testValues() {
+		code, err := i.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transcoded, err := Transcode(code, i.Alphabet, rotated.Alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := rotated.Decode(transcoded); err != nil || decoded != n {
+			t.Errorf("rotated Decode(%q) = %d, %v, want %d", transcoded, decoded, err, n)
+		}
+		if direct, _ := rotated.Encode(n, MinLength); direct != transcoded {
+			t.Errorf("rotated Encode(%d) = %q, want %q", n, direct, transcoded)
+		}
+	}
+	// codes that haven't been migrated don't decode to their value with the copy,
+	// but still do with the original
+	rejected := 0
+	for n := uint64(0); n < 1000; n++ {
+		code, _ := i.Encode(n, MinLength)
+		decoded, err := rotated.Decode(code)
+		if err == nil && decoded == n {
+			t.Errorf("rotated Decode(%q) of an unmigrated code = %d", code, n)
+		}
+		if err != nil {
+			rejected++
+		}
+		if decoded, err := i.Decode(code); err != nil || decoded != n {
+			t.Errorf("original Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+		}
+	}
+	// the rest pass the checksum by chance, about once in 29
+	if rejected < 900 {
+		t.Errorf("rotated Decode rejected %d of 1000 unmigrated codes", rejected)
+	}
+	// without the key, the rotated alphabet alone doesn't decode the same values
+	unkeyed := testEncoder()
+	unkeyed.Alphabet = rotated.Alphabet
+	code, _ := rotated.Encode(123456789, MinLength)
+	if decoded, err := unkeyed.Decode(code); err == nil && decoded == 123456789 {
+		t.Errorf("Decode(%q) without the scramble key = %d", code, decoded)
+	}
+	if _, err := i.Rotate(i.Alphabet[1:]); err == nil {
+		t.Error("Rotate accepted an alphabet of a different length")
+	}
+}
+
+func TestMigrateCodes(t *testing.T) {
+	from := testEncoder()
+	to := testEncoder()
+	to.Alphabet = Alphabet(DefaultAlphabet).Shuffle(9)
+	ids := []uint64{0, 1, 42, 1000, 123456789, 1<<64 - 1}
+	codes, err := MigrateCodes(from, to, ids, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != len(ids) {
+		t.Errorf("MigrateCodes returned %d codes, want %d", len(codes), len(ids))
+	}
+	for _, id := range ids {
+		oldCode, _ := from.Encode(id, MinLength)
+		newCode, ok := codes[oldCode]
+		if !ok {
+			t.Errorf("no migration for %q", oldCode)
+			continue
+		}
+		if oldCode == newCode {
+			t.Errorf("%d encodes to %q under both alphabets", id, oldCode)
+		}
+		if decoded, err := to.Decode(newCode); err != nil || decoded != id {
+			t.Errorf("Decode(%q) = %d, %v, want %d", newCode, decoded, err, id)
+		}
+	}
+	if _, err := MigrateCodes(from, to, ids, DefaultMaxLength+1); err == nil {
+		t.Error("MigrateCodes succeeded with an unencodable minLength")
+	}
+}
@@ -0,0 +1,70 @@
+package idencoder
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Character lookups map an alphabet character to its position. Measured per lookup
+// (see BenchmarkByteLookup), a 256 entry table beats bytes.IndexByte, and both
+// beat a binary search over a sorted table, at every byte alphabet length, so New
+// builds a byteTable. Rune alphabets can't use a table indexed by character, and
+// from sortedLookupMin characters a binary search beats a linear scan.
+
+// sortedLookupMin is the alphabet length from which NewRuneEncoder looks characters
+// up by binary search rather than a linear scan. Below it, a scan is as fast.
+const sortedLookupMin = 32
+
+// byteTable maps each byte to its position in an alphabet, or -1
+type byteTable [256]int16
+
+// newByteTable returns the lookup table for alphabet. A repeated character maps to
+// its first position, like bytes.IndexByte.
+func newByteTable(alphabet []byte) *byteTable {
+	t := new(byteTable)
+	for c := range t {
+		t[c] = -1
+	}
+	for idx := len(alphabet) - 1; idx >= 0; idx-- {
+		t[alphabet[idx]] = int16(idx)
+	}
+	return t
+}
+
+// runeIndex pairs an alphabet character with its position in the alphabet
+type runeIndex struct {
+	char  rune
+	index int
+}
+
+// sortedIndex holds an alphabet in character order for binary search
+type sortedIndex []runeIndex
+
+// newSortedIndex returns the sorted lookup table for alphabet
+func newSortedIndex(alphabet []rune) sortedIndex {
+	s := make(sortedIndex, len(alphabet))
+	for idx, c := range alphabet {
+		s[idx] = runeIndex{c, idx}
+	}
+	// stable, so a repeated character resolves to its first position like a scan
+	sort.SliceStable(s, func(a, b int) bool { return s[a].char < s[b].char })
+	return s
+}
+
+// index returns the position of c in the alphabet, or -1
+func (s sortedIndex) index(c rune) int {
+	pos := sort.Search(len(s), func(idx int) bool { return s[idx].char >= c })
+	if pos < len(s) && s[pos].char == c {
+		return s[pos].index
+	}
+	return -1
+}
+
+// digit returns the position of c in the alphabet, or -1, using the table built by
+// New if there is one
+func (i *IdEncoder) digit(c byte) int {
+	if i.lookup != nil {
+		return int(i.lookup[c])
+	}
+	return bytes.IndexByte(i.Alphabet, c)
+}
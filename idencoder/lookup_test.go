@@ -0,0 +1,126 @@
+package idencoder
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// lookupAlphabet returns an alphabet of n distinct bytes in no particular order
+func lookupAlphabet(n int) []byte {
+	alphabet := make([]byte, n)
+	for idx := range alphabet {
+		// 37 is coprime with 256, so the first 256 values are distinct
+		alphabet[idx] = byte(idx*37 + 11)
+	}
+	return alphabet
+}
+
+// bytesToRunes widens each byte to a rune, so bytes above 127 stay single characters
+func bytesToRunes(alphabet []byte) []rune {
+	runes := make([]rune, len(alphabet))
+	for idx, c := range alphabet {
+		runes[idx] = rune(c)
+	}
+	return runes
+}
+
+func TestByteLookups(t *testing.T) {
+	for _, n := range []int{1, 2, 31, 61, 128, 256} {
+		alphabet := lookupAlphabet(n)
+		table := newByteTable(alphabet)
+		sorted := newSortedIndex(bytesToRunes(alphabet))
+		i := &IdEncoder{Alphabet: alphabet, lookup: table}
+		for c := 0; c < 256; c++ {
+			want := bytes.IndexByte(alphabet, byte(c))
+			if got := int(table[c]); got != want {
+				t.Errorf("%d characters: table lookup of %d = %d, want %d", n, c, got, want)
+			}
+			if got := sorted.index(rune(c)); got != want {
+				t.Errorf("%d characters: sorted lookup of %d = %d, want %d", n, c, got, want)
+			}
+			if got := i.digit(byte(c)); got != want {
+				t.Errorf("%d characters: digit(%d) = %d, want %d", n, c, got, want)
+			}
+		}
+	}
+}
+
+func TestByteTableRepeatedCharacter(t *testing.T) {
+	alphabet := []byte("abcab")
+	table := newByteTable(alphabet)
+	for _, c := range alphabet {
+		if got, want := int(table[c]), bytes.IndexByte(alphabet, c); got != want {
+			t.Errorf("table lookup of %q = %d, want %d", c, got, want)
+		}
+	}
+}
+
+func TestNewBuildsLookup(t *testing.T) {
+	i, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.lookup == nil {
+		t.Fatal("New didn't build a lookup table")
+	}
+	rotated, err := i.Rotate(Alphabet("tvy7fuk4g59d6b3mhc8jqwrzexspan2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := uint64(0); n < 1000; n++ {
+		encoded, err := rotated.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := rotated.Decode(encoded); err != nil || decoded != n {
+			t.Errorf("rotated Decode(%q) = %d, %v, want %d", encoded, decoded, err, n)
+		}
+	}
+}
+
+var lookupSink int
+
+func BenchmarkByteLookup(b *testing.B) {
+	for _, n := range []int{16, 31, 61, 128, 256} {
+		alphabet := lookupAlphabet(n)
+		table := newByteTable(alphabet)
+		sorted := newSortedIndex(bytesToRunes(alphabet))
+		b.Run(fmt.Sprintf("IndexByte/%d", n), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				lookupSink = bytes.IndexByte(alphabet, alphabet[j%n])
+			}
+		})
+		b.Run(fmt.Sprintf("Table/%d", n), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				lookupSink = int(table[alphabet[j%n]])
+			}
+		})
+		b.Run(fmt.Sprintf("Sorted/%d", n), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				lookupSink = sorted.index(rune(alphabet[j%n]))
+			}
+		})
+	}
+}
+
+func BenchmarkRuneLookup(b *testing.B) {
+	for _, n := range []int{16, 32, 61, 256, 4096} {
+		alphabet := make([]rune, n)
+		for idx := range alphabet {
+			alphabet[idx] = rune(0x4e00 + idx*7919%20000)
+		}
+		scan := &RuneEncoder{Alphabet: alphabet}
+		sorted := NewRuneEncoder(alphabet, 0, 1)
+		b.Run(fmt.Sprintf("Scan/%d", n), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				lookupSink = scan.index(alphabet[j%n])
+			}
+		})
+		b.Run(fmt.Sprintf("Sorted/%d", n), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				lookupSink = sorted.index(alphabet[j%n])
+			}
+		})
+	}
+}
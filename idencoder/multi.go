@@ -0,0 +1,70 @@
+package idencoder
+
+import "fmt"
+
+// ErrUnknownTag is returned for a version tag with no registered encoder
+var ErrUnknownTag = &IdEncoderError{Message: "Unknown version tag"}
+
+// MultiDecoder holds several encoders keyed by a version tag, so values encoded with
+// different alphabets can be told apart and decoded side by side, e.g. during an
+// alphabet rotation. Tagged values are the tag character followed by the encoded value.
+type MultiDecoder map[byte]*IdEncoder
+
+// Encode converts an integer to a string using the encoder registered for tag,
+// prefixed with the tag
+func (m MultiDecoder) Encode(tag byte, n, minLength uint64) (encoded string, err error) {
+	i, ok := m[tag]
+	if !ok {
+		return "", ErrUnknownTag
+	}
+	encoded, err = i.Encode(n, minLength)
+	if err != nil {
+		return "", err
+	}
+	return string(tag) + encoded, nil
+}
+
+// Decode reads the version tag at the start of s and decodes the rest using the
+// encoder registered for that tag
+func (m MultiDecoder) Decode(s string) (decoded uint64, err error) {
+	if len(s) == 0 {
+		return 0, ErrTooShort
+	}
+	i, ok := m[s[0]]
+	if !ok {
+		return 0, ErrUnknownTag
+	}
+	return i.Decode(s[1:])
+}
+
+// EncoderPool holds encoders for codes issued under several configurations, such as
+// successive alphabets, when codes carry no tag saying which produced them
+type EncoderPool []*IdEncoder
+
+// PoolError holds the error from each encoder of an EncoderPool that failed to
+// decode a code, in pool order
+type PoolError []error
+
+func (e PoolError) Error() string {
+	if len(e) == 0 {
+		return "IdEncoder error: Encoder pool is empty"
+	}
+	return fmt.Sprintf("IdEncoder error: none of %d encoders decodes the value, first: %v", len(e), e[0])
+}
+
+// Decode tries each encoder in turn, returning the value from the first that
+// decodes s with a valid checksum and its index in the pool. With a single checksum
+// character, a code from one encoder passes another's checksum about once in
+// Checksum attempts, so order the pool from most to least likely. If none
+// matches, err is a PoolError.
+func (p EncoderPool) Decode(s string) (value uint64, which int, err error) {
+	errs := make(PoolError, 0, len(p))
+	for idx, i := range p {
+		decoded, err := i.Decode(s)
+		if err == nil {
+			return decoded, idx, nil
+		}
+		errs = append(errs, err)
+	}
+	return 0, -1, errs
+}
@@ -0,0 +1,74 @@
+package idencoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiDecoder(t *testing.T) {
+	v1 := testEncoder()
+	v2 := testEncoder()
+	v2.Alphabet = Alphabet("tvy7fuk4g59d6b3mhc8jqwrzexspan2")
+	m := MultiDecoder{'1': v1, '2': v2}
+	for _, tag := range []byte{'1', '2'} {
+		for _, n := range []uint64{0, 42, 123456789} {
+			code, err := m.Encode(tag, n, MinLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code[0] != tag {
+				t.Errorf("Encode(%q, %d) = %q, not tagged", tag, n, code)
+			}
+			if decoded, err := m.Decode(code); err != nil || decoded != n {
+				t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+			}
+			untagged, _ := m[tag].Encode(n, MinLength)
+			if code[1:] != untagged {
+				t.Errorf("Encode(%q, %d) = %q, want the tag followed by %q", tag, n, code, untagged)
+			}
+		}
+	}
+	if _, err := m.Encode('3', 1, MinLength); !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("Encode with an unknown tag: %v, want ErrUnknownTag", err)
+	}
+	code, _ := m.Encode('1', 42, MinLength)
+	if _, err := m.Decode("3" + code[1:]); !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("Decode with an unknown tag: %v, want ErrUnknownTag", err)
+	}
+	if _, err := m.Decode(""); !errors.Is(err, ErrTooShort) {
+		t.Errorf("Decode(\"\"): %v, want ErrTooShort", err)
+	}
+}
+
+func TestEncoderPool(t *testing.T) {
+	var pool EncoderPool
+	for _, seed := range []int64{1, 2, 3} {
+		i := testEncoder()
+		i.Alphabet = Alphabet(DefaultAlphabet).Shuffle(seed)
+		pool = append(pool, i)
+	}
+	for which, i := range pool {
+		code, err := i.Encode(123456789, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// this code validates under its own encoder only
+		for other, o := range pool {
+			if _, err := o.Decode(code); (err == nil) != (other == which) {
+				t.Fatalf("encoder %d Decode(%q) error %v", other, code, err)
+			}
+		}
+		value, got, err := pool.Decode(code)
+		if err != nil || value != 123456789 || got != which {
+			t.Errorf("Decode(%q) = %d, %d, %v, want 123456789, %d", code, value, got, err, which)
+		}
+	}
+	_, which, err := pool.Decode("!!!!!!")
+	var poolErr PoolError
+	if which != -1 || !errors.As(err, &poolErr) || len(poolErr) != len(pool) {
+		t.Errorf("Decode(\"!!!!!!\") = %d, %v, want -1 and a PoolError for each encoder", which, err)
+	}
+	if _, _, err := (EncoderPool{}).Decode("x"); err == nil {
+		t.Error("an empty pool decoded a value")
+	}
+}
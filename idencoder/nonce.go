@@ -0,0 +1,67 @@
+package idencoder
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// nonceLength is the number of characters of nonce added by EncodeNonced
+const nonceLength = 2
+
+// EncodeNonced converts an integer to a string like Encode, but mixes in a random
+// nonce so that repeated calls produce different strings for the same integer.
+// The nonce is mixed into both the data and the checksum, and carried in
+// nonceLength extra characters after any Prefix. The result must be decoded with
+// DecodeNonced.
+func (i *IdEncoder) EncodeNonced(n, minLength uint64) (encoded string, err error) {
+	limit := new(big.Int).Exp(big.NewInt(int64(len(i.Alphabet))), big.NewInt(nonceLength), nil)
+	r, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	nonce := r.Uint64()
+	encoded, err = i.withNonce(nonce).Encode(n, minLength)
+	if err != nil {
+		return "", err
+	}
+	p := len(i.Prefix)
+	return encoded[:p] + i.enbase(nonce, nonceLength) + encoded[p:], nil
+}
+
+// DecodeNonced converts a string produced by EncodeNonced back to an integer
+func (i *IdEncoder) DecodeNonced(s string) (decoded uint64, err error) {
+	if !strings.HasPrefix(s, i.Prefix) {
+		return 0, ErrMissingPrefix
+	}
+	p := len(i.Prefix)
+	if len(s) < p+nonceLength {
+		return 0, ErrTooShort
+	}
+	nonce, err := i.debase([]byte(s[p : p+nonceLength]))
+	if invalid := invalidCharacter(err); invalid != nil {
+		invalid.Index += p
+	}
+	if err != nil {
+		return 0, err
+	}
+	// the prefix is already checked, so the rest is decoded without it rather than
+	// copied back together
+	e := i.withNonce(nonce)
+	e.Prefix = ""
+	decoded, err = e.Decode(s[p+nonceLength:])
+	if invalid := invalidCharacter(err); invalid != nil {
+		invalid.Index += p + nonceLength
+	}
+	return decoded, err
+}
+
+// withNonce returns a copy of the encoder with the nonce mixed into its salt and,
+// since the salt doesn't affect every checksum mode, into its checksum as well
+func (i *IdEncoder) withNonce(nonce uint64) *IdEncoder {
+	mixed := (nonce + 1) * 0x9e3779b97f4a7c15
+	e := *i
+	e.Salt ^= mixed
+	e.aad = (e.aad + mixed>>32) | 1
+	return &e
+}
@@ -0,0 +1,67 @@
+package idencoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeNonced(t *testing.T) {
+	prefixed := testEncoder()
+	prefixed.Prefix = "id_"
+	for _, i := range []*IdEncoder{testEncoder(), prefixed} {
+		const n, count = 777, 50
+		codes := map[string]bool{}
+		// the checksum follows the prefix and nonce
+		checksums := map[byte]bool{}
+		for k := 0; k < count; k++ {
+			code, err := i.EncodeNonced(n, MinLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(code, i.Prefix) {
+				t.Errorf("EncodeNonced(%d) = %q, want prefix %q", n, code, i.Prefix)
+			}
+			if decoded, err := i.DecodeNonced(code); err != nil || decoded != n {
+				t.Errorf("DecodeNonced(%q) = %d, %v, want %d", code, decoded, err, n)
+			}
+			codes[code] = true
+			checksums[code[len(i.Prefix)+nonceLength]] = true
+		}
+		// 50 draws from 961 nonces all landing on a few is vanishingly unlikely
+		if len(codes) < count/2 {
+			t.Errorf("prefix %q: %d encodes of %d gave %d distinct codes", i.Prefix, count, n, len(codes))
+		}
+		if len(checksums) < 5 {
+			t.Errorf("prefix %q: %d encodes of %d gave %d distinct checksums, want the checksum to vary too", i.Prefix, count, n, len(checksums))
+		}
+	}
+}
+
+func TestDecodeNoncedErrors(t *testing.T) {
+	i := testEncoder()
+	i.Prefix = "id_"
+	code, err := i.EncodeNonced(777, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.DecodeNonced(code[len(i.Prefix):]); !errors.Is(err, ErrMissingPrefix) {
+		t.Errorf("DecodeNonced without the prefix error %v, want ErrMissingPrefix", err)
+	}
+	if _, err := i.DecodeNonced("id_f"); !errors.Is(err, ErrTooShort) {
+		t.Errorf("DecodeNonced(\"id_f\") error %v, want ErrTooShort", err)
+	}
+	for _, idx := range []int{3, 7} {
+		bad := code[:idx] + "!" + code[idx+1:]
+		var invalid *InvalidCharacterError
+		if _, err := i.DecodeNonced(bad); !errors.As(err, &invalid) || invalid.Index != idx {
+			t.Errorf("DecodeNonced(%q) error %v, want an invalid character at index %d", bad, err, idx)
+		}
+	}
+	// the code only decodes under its own nonce
+	other := i.Alphabet[(i.digit(code[4])+1)%len(i.Alphabet)]
+	changed := code[:4] + string(other) + code[5:]
+	if decoded, err := i.DecodeNonced(changed); err == nil && decoded == 777 {
+		t.Errorf("DecodeNonced(%q) with another nonce = 777", changed)
+	}
+}
@@ -0,0 +1,79 @@
+package idencoder
+
+// Option configures an IdEncoder created with New
+type Option func(*IdEncoder)
+
+// New returns an IdEncoder configured by opts, returning an error if the resulting
+// configuration is invalid. Unspecified settings use DefaultAlphabet,
+// DefaultBlockSize, DefaultChecksum and MinLength. The encoder precomputes a
+// character lookup table, so its Alphabet must not be modified afterwards.
+func New(opts ...Option) (*IdEncoder, error) {
+	i := &IdEncoder{
+		Alphabet:  Alphabet(DefaultAlphabet),
+		BlockSize: DefaultBlockSize,
+		Checksum:  DefaultChecksum,
+		MinLength: MinLength,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	if err := i.Validate(); err != nil {
+		return nil, err
+	}
+	_, i.checksumFunc = i.checksumAlgorithm()
+	i.lookup = newByteTable(i.Alphabet)
+	return i, nil
+}
+
+// WithAlphabet sets the alphabet
+func WithAlphabet(alphabet string) Option {
+	return func(i *IdEncoder) {
+		i.Alphabet = Alphabet(alphabet)
+	}
+}
+
+// WithBlockSize sets the number of bits shuffled
+func WithBlockSize(blockSize uint64) Option {
+	return func(i *IdEncoder) {
+		i.BlockSize = BlockSize(blockSize)
+	}
+}
+
+// WithChecksum sets the checksum modulus
+func WithChecksum(checksum uint64) Option {
+	return func(i *IdEncoder) {
+		i.Checksum = Checksum(checksum)
+	}
+}
+
+// WithMinLength sets the encoder's default minimum data length
+func WithMinLength(minLength uint64) Option {
+	return func(i *IdEncoder) {
+		i.MinLength = minLength
+	}
+}
+
+// WithPrefix sets the prefix prepended to every encoded value
+func WithPrefix(prefix string) Option {
+	return func(i *IdEncoder) {
+		i.Prefix = prefix
+	}
+}
+
+// URLSafeAlphabet is the alphabet used by NewURLSafe: the 64 characters that need
+// no escaping in URLs, less the look-alikes 'l', 'I' and 'O' for a prime length
+const URLSafeAlphabet = "8EPk-aCTshNGWoeRLSntVYXcf9MJ4Awp5BUQFu3dZy2xHqDgvr_b0K7i6m1jz"
+
+// NewURLSafe returns an encoder for short URL codes with URLSafeAlphabet, a 32 bit
+// block and a checksum over the whole alphabet. The alphabet is fixed and public,
+// so its codes only obscure values; call SetScrambleKey with a secret key when
+// values must not be recoverable by others.
+func NewURLSafe() *IdEncoder {
+	return &IdEncoder{
+		Alphabet:  Alphabet(URLSafeAlphabet),
+		BlockSize: 32,
+		Checksum:  Checksum(len(URLSafeAlphabet)),
+		MinLength: MinLength,
+		lookup:    newByteTable([]byte(URLSafeAlphabet)),
+	}
+}
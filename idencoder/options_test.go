@@ -0,0 +1,77 @@
+package idencoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewDefaults(t *testing.T) {
+	i, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(i.Alphabet) != DefaultAlphabet || i.BlockSize != DefaultBlockSize || i.Checksum != DefaultChecksum || i.MinLength != MinLength {
+		t.Errorf("New() = %q, %d, %d, %d, want the defaults", i.Alphabet, i.BlockSize, i.Checksum, i.MinLength)
+	}
+}
+
+func TestNewOptionsCompose(t *testing.T) {
+	i, err := New(
+		WithAlphabet("0123456789abcdef"),
+		WithBlockSize(16),
+		WithChecksum(13),
+		WithMinLength(6),
+		WithPrefix("x_"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(i.Alphabet) != "0123456789abcdef" || i.BlockSize != 16 || i.Checksum != 13 || i.MinLength != 6 || i.Prefix != "x_" {
+		t.Errorf("New(...) = %q, %d, %d, %d, %q", i.Alphabet, i.BlockSize, i.Checksum, i.MinLength, i.Prefix)
+	}
+	code, err := i.Encode(1234, i.MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(code, "x_") || len(code) != len("x_")+1+6 {
+		t.Errorf("Encode(1234) = %q, want x_ and 7 characters", code)
+	}
+	if decoded, err := i.Decode(code); err != nil || decoded != 1234 {
+		t.Errorf("Decode(%q) = %d, %v, want 1234", code, decoded, err)
+	}
+	// later options override earlier ones
+	i, err = New(WithChecksum(7), WithChecksum(11))
+	if err != nil || i.Checksum != 11 {
+		t.Errorf("New(WithChecksum(7), WithChecksum(11)) = %v, %v, want Checksum 11", i, err)
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"short alphabet", []Option{WithAlphabet("a")}},
+		{"duplicate character", []Option{WithAlphabet("abca")}},
+		{"large block", []Option{WithBlockSize(65)}},
+		{"zero checksum", []Option{WithChecksum(0)}},
+		{"checksum beyond alphabet", []Option{WithAlphabet("0123456789"), WithChecksum(11)}},
+		{"long minimum", []Option{WithMinLength(DefaultMaxLength + 1)}},
+	} {
+		if i, err := New(tc.opts...); err == nil {
+			t.Errorf("%s: New = %v, want an error", tc.name, i)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	i := testEncoder()
+	if radix, padChar, checksumLen := i.Params(); radix != len(DefaultAlphabet) || padChar != DefaultAlphabet[0] || checksumLen != 1 {
+		t.Errorf("Params() = %d, %q, %d, want %d, %q, 1", radix, padChar, checksumLen, len(DefaultAlphabet), DefaultAlphabet[0])
+	}
+	i.Alphabet = Alphabet("0123456789")
+	i.PadChar = '.'
+	if radix, padChar, checksumLen := i.Params(); radix != 10 || padChar != '.' || checksumLen != 1 {
+		t.Errorf("Params() = %d, %q, %d, want 10, '.', 1", radix, padChar, checksumLen)
+	}
+}
@@ -0,0 +1,213 @@
+package idencoder
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxAlphabetSize is the largest alphabet of distinct byte characters
+const maxAlphabetSize = 256
+
+// Config is a suggested set of encoder parameters
+type Config struct {
+	// AlphabetSize is the number of characters the alphabet should contain
+	AlphabetSize int
+	BlockSize    BlockSize
+	Checksum     Checksum
+	// MinLength is the minLength to pass to Encode for uniform width codes
+	MinLength uint64
+}
+
+// SuggestConfig returns the smallest alphabet size that encodes every value up to
+// maxValue in desiredLen characters, including the checksum, along with a block size
+// from RecommendedBlockSize and a prime checksum modulus. Because scrambling can set
+// any of the block's bits, the data characters must cover the whole block, not just
+// maxValue. Returns an error if no alphabet of byte characters is large enough.
+func SuggestConfig(maxValue, desiredLen uint64) (Config, error) {
+	if desiredLen < 2 {
+		return Config{}, &IdEncoderError{
+			Message: "Desired length must allow for a checksum and at least one data character",
+		}
+	}
+	blockSize := RecommendedBlockSize(maxValue)
+	digits := desiredLen - 1
+	// every scrambled value is below 2^blockSize, or maxValue when unscrambled
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(blockSize))
+	for radix := 2; radix <= maxAlphabetSize; radix++ {
+		capacity := new(big.Int).Exp(big.NewInt(int64(radix)), new(big.Int).SetUint64(digits), nil)
+		if capacity.Cmp(limit) < 0 {
+			continue
+		}
+		checksum := radix
+		for !isPrime(checksum) {
+			checksum--
+		}
+		return Config{
+			AlphabetSize: radix,
+			BlockSize:    BlockSize(blockSize),
+			Checksum:     Checksum(checksum),
+			MinLength:    digits,
+		}, nil
+	}
+	return Config{}, &IdEncoderError{
+		Message: fmt.Sprintf("%d data characters can't represent %d bits with an alphabet of at most %d characters",
+			digits, blockSize, maxAlphabetSize),
+	}
+}
+
+// MinAlphabetSize returns the smallest alphabet size whose codes of at most maxLen
+// characters, including the checksum, can represent keyspace distinct values.
+// Scrambling isn't taken into account; see SuggestConfig for that. Returns an
+// error if no alphabet of byte characters is large enough.
+func MinAlphabetSize(keyspace uint64, maxLen int) (int, error) {
+	if maxLen < 2 {
+		return 0, &IdEncoderError{
+			Message: "Maximum length must allow for a checksum and at least one data character",
+		}
+	}
+	target := new(big.Int).SetUint64(keyspace)
+	for radix := 2; radix <= maxAlphabetSize; radix++ {
+		capacity := new(big.Int).Exp(big.NewInt(int64(radix)), big.NewInt(int64(maxLen-1)), nil)
+		if capacity.Cmp(target) >= 0 {
+			return radix, nil
+		}
+	}
+	return 0, &IdEncoderError{
+		Message: fmt.Sprintf("%d data characters can't represent %d values with an alphabet of at most %d characters",
+			maxLen-1, keyspace, maxAlphabetSize),
+	}
+}
+
+// ErrInsufficientLength is returned when a length is too short to encode the values asked of it
+var ErrInsufficientLength = &IdEncoderError{Message: "Length too short for the scramble block"}
+
+// Capacity returns the number of distinct values that can be represented in length
+// data characters, excluding the checksum
+func (i *IdEncoder) Capacity(length int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(len(i.Alphabet))), big.NewInt(int64(length)), nil)
+}
+
+// MaxValue returns the largest value v such that every value in [0, v] encodes to at
+// most length data characters, excluding the checksum. Because scrambling can set any
+// bit of the block, only whole blocks fit, and ErrInsufficientLength is returned if
+// length can't hold a single block. Salt is not taken into account.
+func (i *IdEncoder) MaxValue(length int) (uint64, error) {
+	span := i.blockSpan()
+	blocks := new(big.Int).Quo(i.Capacity(length), span)
+	if blocks.Sign() == 0 {
+		return 0, ErrInsufficientLength
+	}
+	max := blocks.Mul(blocks, span)
+	max.Sub(max, big.NewInt(1))
+	if !max.IsUint64() {
+		return math.MaxUint64, nil
+	}
+	return max.Uint64(), nil
+}
+
+// CapacityTable returns a table of code lengths, from a single data character up to
+// maxLen data characters, with the number of values each can represent and the
+// largest value below which every value fits
+func (i *IdEncoder) CapacityTable(maxLen int) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Code length\tData length\tCapacity\tMax value\t")
+	for length := 1; length <= maxLen; length++ {
+		maxValue := "-"
+		if v, err := i.MaxValue(length); err == nil {
+			maxValue = strconv.FormatUint(v, 10)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t\n", length+1, length, i.Capacity(length), maxValue)
+	}
+	w.Flush()
+	return sb.String()
+}
+
+// blockSpan returns the number of distinct values the scramble may map a value within
+// a block to, which is the granularity at which values fit a given length
+func (i *IdEncoder) blockSpan() *big.Int {
+	switch {
+	case i.scrambleKey != nil:
+		return new(big.Int).Lsh(big.NewInt(1), 64)
+	case i.AlignBlock:
+		return new(big.Int).SetUint64(i.digitSpan())
+	case i.BlockSize >= 64:
+		return new(big.Int).Lsh(big.NewInt(1), 64)
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(i.BlockSize))
+}
+
+// PlanFixedWidth returns the minLength to pass to Encode so every value up to
+// maxValue encodes to the same width, along with that width in characters,
+// including the checksum and any prefix, decoys and separators. Returns
+// ErrInsufficientLength if maxValue doesn't fit within the encoder's MaxLength.
+func (i *IdEncoder) PlanFixedWidth(maxValue uint64) (minLength uint64, totalWidth int, err error) {
+	for length := 1; uint64(length) <= i.maxLength(); length++ {
+		if v, err := i.MaxValue(length); err == nil && v >= maxValue {
+			return uint64(length), i.encodedWidth(length), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: %d doesn't fit in %d data characters", ErrInsufficientLength, maxValue, i.maxLength())
+}
+
+// EncodedLength returns the length of the string Encode produces for n with the
+// encoder's MinLength, without encoding it. Like every length reported by the
+// encoder, this is the total visible length: len(Prefix) + DecoyPrefixLen + 1 for
+// the checksum + the data characters, plus (data-1)/GroupSize separators when
+// grouping splits the data.
+func (i *IdEncoder) EncodedLength(n uint64) int {
+	x := i.scramble(n ^ i.Salt)
+	radix := uint64(len(i.Alphabet))
+	digits := uint64(1)
+	for x /= radix; x > 0; x /= radix {
+		digits++
+	}
+	if min := i.minLength(); digits < min {
+		digits = min
+	}
+	return i.encodedWidth(int(digits))
+}
+
+// FixedWidthLength returns the total length, as counted by EncodedLength, that
+// holds the encoding of any value: enough data characters for math.MaxUint64, or
+// the encoder's MinLength if longer. Passing FixedWidthLength's data length as
+// minLength gives every code this width.
+func (i *IdEncoder) FixedWidthLength() int {
+	digits := i.maxDigits()
+	if min := int(i.minLength()); digits < min {
+		digits = min
+	}
+	return i.encodedWidth(digits)
+}
+
+// encodedWidth returns the total length of an encoded value with dataLen data characters
+func (i *IdEncoder) encodedWidth(dataLen int) int {
+	width := len(i.Prefix) + i.DecoyPrefixLen + 1 + dataLen
+	if i.GroupSize > 0 && dataLen > i.GroupSize {
+		width += (dataLen - 1) / i.GroupSize
+	}
+	return width
+}
+
+// RemainingCapacity returns how many values above currentMax can still be encoded
+// within width characters, counted like EncodedLength: the checksum, and any
+// prefix, decoys and separators, take up part of the width. Returns
+// ErrInsufficientLength if currentMax itself doesn't fit.
+func (i *IdEncoder) RemainingCapacity(currentMax uint64, width int) (uint64, error) {
+	dataLen := 0
+	for i.encodedWidth(dataLen+1) <= width {
+		dataLen++
+	}
+	max, err := i.MaxValue(dataLen)
+	if err != nil {
+		return 0, err
+	}
+	if currentMax > max {
+		return 0, fmt.Errorf("%w: %d doesn't fit in width %d", ErrInsufficientLength, currentMax, width)
+	}
+	return max - currentMax, nil
+}
@@ -0,0 +1,230 @@
+package idencoder
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSuggestConfig(t *testing.T) {
+	for _, tc := range []struct {
+		maxValue, desiredLen uint64
+		alphabetSize         int
+	}{
+		{1000, 4, 11},            // 10 bits in 3 characters
+		{1 << 20, 6, 19},         // 21 bits in 5 characters
+		{math.MaxUint64, 14, 31}, // 64 bits in 13 characters
+	} {
+		config, err := SuggestConfig(tc.maxValue, tc.desiredLen)
+		if err != nil {
+			t.Errorf("SuggestConfig(%d, %d): %v", tc.maxValue, tc.desiredLen, err)
+			continue
+		}
+		if config.AlphabetSize != tc.alphabetSize {
+			t.Errorf("SuggestConfig(%d, %d).AlphabetSize = %d, want %d", tc.maxValue, tc.desiredLen, config.AlphabetSize, tc.alphabetSize)
+		}
+		if uint64(config.BlockSize) != RecommendedBlockSize(tc.maxValue) {
+			t.Errorf("SuggestConfig(%d, %d).BlockSize = %d, want %d", tc.maxValue, tc.desiredLen, config.BlockSize, RecommendedBlockSize(tc.maxValue))
+		}
+		i := &IdEncoder{
+			Alphabet:  Alphabet(DefaultAlphabet + "ABCDEFGHIJKLMNOPQRSTUVWXYZ")[:config.AlphabetSize],
+			BlockSize: config.BlockSize,
+			Checksum:  config.Checksum,
+		}
+		if err := i.Validate(); err != nil {
+			t.Errorf("SuggestConfig(%d, %d) gives an invalid encoder: %v", tc.maxValue, tc.desiredLen, err)
+		}
+		for _, n := range []uint64{0, tc.maxValue / 2, tc.maxValue} {
+			encoded, err := i.Encode(n, config.MinLength)
+			if err != nil || uint64(len(encoded)) != tc.desiredLen {
+				t.Errorf("Encode(%d) with SuggestConfig(%d, %d) = %q, %v, want %d characters", n, tc.maxValue, tc.desiredLen, encoded, err, tc.desiredLen)
+			}
+		}
+	}
+}
+
+func TestSuggestConfigInfeasible(t *testing.T) {
+	for _, tc := range []struct{ maxValue, desiredLen uint64 }{
+		{1000, 1},
+		{math.MaxUint64, 8},
+	} {
+		if config, err := SuggestConfig(tc.maxValue, tc.desiredLen); err == nil {
+			t.Errorf("SuggestConfig(%d, %d) = %+v, want an error", tc.maxValue, tc.desiredLen, config)
+		}
+	}
+}
+
+func TestCapacityTable(t *testing.T) {
+	decimal := testEncoder()
+	decimal.Alphabet = Alphabet("0123456789")
+	decimal.BlockSize = 0
+	for _, tc := range []struct {
+		i    *IdEncoder
+		rows int
+		want map[int][]string
+	}{
+		{decimal, 3, map[int][]string{
+			1: {"2", "1", "10", "9"},
+			3: {"4", "3", "1000", "999"},
+		}},
+		// 31^4 is smaller than the 2^24 values a block can be scrambled to
+		{testEncoder(), 14, map[int][]string{
+			4:  {"5", "4", "923521", "-"},
+			5:  {"6", "5", "28629151", "16777215"},
+			14: {"15", "14", "756943935220796320321", "18446744073709551615"},
+		}},
+	} {
+		lines := strings.Split(strings.TrimSuffix(tc.i.CapacityTable(tc.rows), "\n"), "\n")
+		if len(lines) != tc.rows+1 {
+			t.Errorf("CapacityTable(%d) has %d lines, want a header and %d rows", tc.rows, len(lines), tc.rows)
+			continue
+		}
+		for row, want := range tc.want {
+			if got := strings.Fields(lines[row]); strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("CapacityTable(%d) row %d = %q, want %q", tc.rows, row, got, want)
+			}
+		}
+	}
+}
+
+func TestPlanFixedWidth(t *testing.T) {
+	grouped := testEncoder()
+	grouped.Prefix = "id_"
+	grouped.GroupSize = 4
+	tests := []struct {
+		i         *IdEncoder
+		maxValue  uint64
+		minLength uint64
+		width     int
+	}{
+		// 31^4 can't hold a 24-bit block, so even small values need 5 characters
+		{testEncoder(), 1000, 5, 6},
+		{testEncoder(), 16777215, 5, 6},
+		{testEncoder(), 16777216, 6, 7},
+		{testEncoder(), math.MaxUint64, 13, 14},
+		// "id_", the checksum and 13 data characters in 4 groups
+		{grouped, math.MaxUint64, 13, 3 + 1 + 13 + 3},
+	}
+	for _, tt := range tests {
+		minLength, width, err := tt.i.PlanFixedWidth(tt.maxValue)
+		if err != nil || minLength != tt.minLength || width != tt.width {
+			t.Errorf("PlanFixedWidth(%d) = %d, %d, %v, want %d, %d", tt.maxValue, minLength, width, err, tt.minLength, tt.width)
+			continue
+		}
+		for _, n := range []uint64{0, tt.maxValue / 3, tt.maxValue} {
+			if code, err := tt.i.Encode(n, minLength); err != nil || len(code) != width {
+				t.Errorf("Encode(%d, %d) = %q, %v, want %d characters", n, minLength, code, err, width)
+			}
+		}
+	}
+	short := testEncoder()
+	short.MaxLength = 4
+	if _, _, err := short.PlanFixedWidth(1000); !errors.Is(err, ErrInsufficientLength) {
+		t.Errorf("PlanFixedWidth(1000) with MaxLength 4 error %v, want ErrInsufficientLength", err)
+	}
+}
+
+func TestEncodedLength(t *testing.T) {
+	configs := map[string]func(i *IdEncoder){
+		"plain":    func(i *IdEncoder) {},
+		"prefix":   func(i *IdEncoder) { i.Prefix = "id_" },
+		"grouped":  func(i *IdEncoder) { i.GroupSize = 3 },
+		"decoys":   func(i *IdEncoder) { i.DecoyPrefixLen = 2 },
+		"trailing": func(i *IdEncoder) { i.ChecksumPlacement = ChecksumTrailing; i.GroupSize = 4 },
+		"everything": func(i *IdEncoder) {
+			i.Prefix = "x-"
+			i.DecoyPrefixLen = 1
+			i.GroupSize = 2
+			i.ChecksumPlacement = ChecksumInterleaved
+			i.MinLength = 9
+		},
+	}
+	for name, configure := range configs {
+		i := testEncoder()
+		configure(i)
+		for _, n := range testValues() {
+			code, err := i.Encode(n, i.minLength())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := i.EncodedLength(n); got != len(code) {
+				t.Errorf("%s: EncodedLength(%d) = %d, but Encode gives %q", name, n, got, code)
+			}
+		}
+		code, err := i.Encode(math.MaxUint64, uint64(i.maxDigits()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := i.FixedWidthLength(); got != len(code) {
+			t.Errorf("%s: FixedWidthLength() = %d, but the largest value encodes to %q", name, got, code)
+		}
+	}
+}
+
+func TestMinAlphabetSize(t *testing.T) {
+	tests := []struct {
+		keyspace uint64
+		maxLen   int
+		want     int
+	}{
+		{1000, 4, 10},            // 10^3 = 1000
+		{1001, 4, 11},            // 10^3 falls one short
+		{1, 2, 2},                // a single value still needs a two character alphabet
+		{1 << 32, 9, 16},         // 16^8 = 2^32
+		{math.MaxUint64, 14, 31}, // 30^13 < 2^64 - 1 <= 31^13
+	}
+	for _, tt := range tests {
+		if got, err := MinAlphabetSize(tt.keyspace, tt.maxLen); err != nil || got != tt.want {
+			t.Errorf("MinAlphabetSize(%d, %d) = %d, %v, want %d", tt.keyspace, tt.maxLen, got, err, tt.want)
+		}
+	}
+	for _, tt := range []struct {
+		keyspace uint64
+		maxLen   int
+	}{
+		{1000000, 2}, // more than 256 values in one data character
+		{10, 1},      // no room for data
+	} {
+		if got, err := MinAlphabetSize(tt.keyspace, tt.maxLen); err == nil {
+			t.Errorf("MinAlphabetSize(%d, %d) = %d, want an error", tt.keyspace, tt.maxLen, got)
+		}
+	}
+}
+
+func TestRemainingCapacity(t *testing.T) {
+	decimal := testEncoder()
+	decimal.Alphabet = Alphabet("0123456789")
+	decimal.BlockSize = 0
+	prefixed := testEncoder()
+	prefixed.Alphabet = decimal.Alphabet
+	prefixed.BlockSize = 0
+	prefixed.Prefix = "id_"
+	tests := []struct {
+		name       string
+		i          *IdEncoder
+		currentMax uint64
+		width      int
+		want       uint64
+	}{
+		// the checksum takes one of the 4 characters, leaving 3 digits
+		{"fresh", decimal, 0, 4, 999},
+		{"near exhausted", decimal, 995, 4, 4},
+		{"exhausted", decimal, 999, 4, 0},
+		{"prefixed", prefixed, 990, 7, 9},
+		// 5 data characters hold the 24-bit block
+		{"scrambled", testEncoder(), 16777000, 6, 215},
+	}
+	for _, tt := range tests {
+		if got, err := tt.i.RemainingCapacity(tt.currentMax, tt.width); err != nil || got != tt.want {
+			t.Errorf("%s: RemainingCapacity(%d, %d) = %d, %v, want %d", tt.name, tt.currentMax, tt.width, got, err, tt.want)
+		}
+	}
+	if _, err := decimal.RemainingCapacity(1000, 4); !errors.Is(err, ErrInsufficientLength) {
+		t.Errorf("RemainingCapacity(1000, 4) error %v, want ErrInsufficientLength", err)
+	}
+	// 31^4 can't hold a whole block, whatever the current maximum
+	if _, err := testEncoder().RemainingCapacity(0, 5); !errors.Is(err, ErrInsufficientLength) {
+		t.Errorf("RemainingCapacity(0, 5) error %v, want ErrInsufficientLength", err)
+	}
+}
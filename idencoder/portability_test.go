@@ -0,0 +1,60 @@
+package idencoder
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Codes persist across platforms, so these are fixed: any change in the arithmetic,
+// or a dependence on word size or byte order, breaks them.
+func TestGoldenCodes(t *testing.T) {
+	i := testEncoder()
+	tests := []struct {
+		n    uint64
+		code string
+	}{
+		{0, "333333"},
+		{1, "fhqyf7"},
+		{1000, "nf9zwf"},
+		{123456789, "gr8r7s9"},
+		{1 << 32, "bre3gu2r"},
+		{1<<64 - 1, "jjm3zvydrvw758"},
+	}
+	for _, tt := range tests {
+		if code, err := i.Encode(tt.n, MinLength); err != nil || code != tt.code {
+			t.Errorf("Encode(%d) = %q, %v, want %q", tt.n, code, err, tt.code)
+		}
+	}
+	// as in the original version, zero with no minimum length is the checksum alone
+	if code, err := i.Encode(0, 0); err != nil || code != "3" {
+		t.Errorf("Encode(0, 0) = %q, %v, want \"3\"", code, err)
+	}
+	if decoded, err := i.Decode("3"); err != nil || decoded != 0 {
+		t.Errorf("Decode(\"3\") = %d, %v, want 0", decoded, err)
+	}
+	if code, _ := keyedEncoder("secret").Encode(123456789, MinLength); code != "g5udcvzp98b7kk" {
+		t.Errorf("keyed Encode(123456789) = %q, want \"g5udcvzp98b7kk\"", code)
+	}
+	if code, _ := i.EncodeBlob([]byte{1, 2, 3}, 0); code != "dqs6tfj" {
+		t.Errorf("EncodeBlob({1, 2, 3}) = %q, want \"dqs6tfj\"", code)
+	}
+}
+
+// EncodeToBytes writes digits most significant first, matching the characters of
+// Encode, whatever the platform's byte order
+func TestEncodeToBytesIsBigEndian(t *testing.T) {
+	i := testEncoder()
+	b, err := i.EncodeToBytes(1000, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{14, 1, 21, 7, 20, 1}; !bytes.Equal(b, want) {
+		t.Errorf("EncodeToBytes(1000, 6) = %v, want %v", b, want)
+	}
+	code, _ := i.Encode(1000, 5)
+	for idx := range b {
+		if i.Alphabet[b[idx]] != code[idx] {
+			t.Errorf("EncodeToBytes(1000, 6)[%d] = %d, but Encode has %q there", idx, b[idx], code[idx])
+		}
+	}
+}
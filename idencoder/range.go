@@ -0,0 +1,37 @@
+package idencoder
+
+import "context"
+
+// RangeContext lazily encodes each integer in [start, end) and sends the results,
+// in order, on the returned channel. The channel is closed when the range is
+// exhausted, an integer fails to encode, or ctx is done, so cancel ctx to stop
+// early without leaking the producing goroutine. The returned function waits for
+// the producer to finish and reports why it stopped: nil if the range was
+// exhausted, the encoding error, or ctx.Err(). Call it after draining the channel
+// or cancelling ctx.
+func (i *IdEncoder) RangeContext(ctx context.Context, start, end, minLength uint64) (<-chan string, func() error) {
+	ch := make(chan string)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		defer close(ch)
+		for n := start; n < end; n++ {
+			encoded, encodeErr := i.Encode(n, minLength)
+			if encodeErr != nil {
+				err = encodeErr
+				return
+			}
+			select {
+			case ch <- encoded:
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			}
+		}
+	}()
+	return ch, func() error {
+		<-done
+		return err
+	}
+}
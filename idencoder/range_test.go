@@ -0,0 +1,71 @@
+package idencoder
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRangeContextOrder(t *testing.T) {
+	i := testEncoder()
+	codes, rangeErr := i.RangeContext(context.Background(), 995, 1010, MinLength)
+	n := uint64(995)
+	for code := range codes {
+		if want, _ := i.Encode(n, MinLength); code != want {
+			t.Errorf("code %d = %q, want %q", n-995, code, want)
+		}
+		n++
+	}
+	if n != 1010 {
+		t.Errorf("got %d codes, want 15", n-995)
+	}
+	if err := rangeErr(); err != nil {
+		t.Errorf("error after exhausting the range = %v, want nil", err)
+	}
+}
+
+func TestRangeContextEncodeError(t *testing.T) {
+	// 31*31 values fit in two data characters
+	i := testEncoder()
+	i.BlockSize = 0
+	i.FixedWidth = true
+	codes, rangeErr := i.RangeContext(context.Background(), 955, 970, 2)
+	got := 0
+	for range codes {
+		got++
+	}
+	if got != 6 {
+		t.Errorf("got %d codes, want the 6 that fit", got)
+	}
+	if err := rangeErr(); err == nil {
+		t.Error("error after a value fails to encode = nil")
+	}
+}
+
+func TestRangeContextCancel(t *testing.T) {
+	i := testEncoder()
+	before := runtime.NumGoroutine()
+	for k := 0; k < 100; k++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		codes, rangeErr := i.RangeContext(ctx, 0, 1000, MinLength)
+		for j := 0; j < 3; j++ {
+			<-codes
+		}
+		cancel()
+		if err := rangeErr(); err != context.Canceled {
+			t.Fatalf("error after cancelling = %v, want context.Canceled", err)
+		}
+		if _, ok := <-codes; ok {
+			t.Fatal("channel still open after cancelling")
+		}
+	}
+	// the producers have finished, but may not have been reaped yet
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before, %d after cancelling 100 ranges", before, after)
+	}
+}
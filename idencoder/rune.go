@@ -0,0 +1,107 @@
+package idencoder
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// RuneEncoder is an IdEncoder for alphabets of arbitrary Unicode characters, such as
+// emoji or CJK characters. Encoded values are indexed by rune rather than by byte,
+// including the checksum. Use IdEncoder for ASCII alphabets, which is faster.
+type RuneEncoder struct {
+	Alphabet  []rune
+	BlockSize BlockSize
+	Checksum  Checksum
+
+	// sorted holds the alphabet in rune order for binary search, if built by NewRuneEncoder
+	sorted sortedIndex
+}
+
+// NewRuneEncoder returns a RuneEncoder for alphabet. For alphabets of 32 or more
+// characters, it precomputes a sorted lookup table so decoding takes logarithmic
+// rather than linear time per character; the alphabet must not be modified
+// afterwards.
+func NewRuneEncoder(alphabet []rune, blockSize BlockSize, checksum Checksum) *RuneEncoder {
+	r := &RuneEncoder{Alphabet: alphabet, BlockSize: blockSize, Checksum: checksum}
+	if len(alphabet) >= sortedLookupMin {
+		r.sorted = newSortedIndex(alphabet)
+	}
+	return r
+}
+
+// Encode converts an integer to a unique string, using the parameters contained in the RuneEncoder
+func (r *RuneEncoder) Encode(n, minLength uint64) (encoded string, err error) {
+	base := uint64(len(r.Alphabet))
+	x := Scramble(n, uint64(r.BlockSize))
+	var digits []rune
+	for x > 0 {
+		digits = append(digits, r.Alphabet[x%base])
+		x /= base
+	}
+	// zero gets one data character, so Decode accepts it
+	for uint64(len(digits)) < minLength || len(digits) == 0 {
+		digits = append(digits, r.Alphabet[0])
+	}
+	out := make([]rune, 0, len(digits)+1)
+	out = append(out, r.checksum(n))
+	for idx := len(digits) - 1; idx >= 0; idx-- {
+		out = append(out, digits[idx])
+	}
+	return string(out), nil
+}
+
+// Decode converts a string to an integer, using the parameters contained in the RuneEncoder.
+// Character positions in errors are counted in runes.
+func (r *RuneEncoder) Decode(s string) (decoded uint64, err error) {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return 0, ErrTooShort
+	}
+	base := uint64(len(r.Alphabet))
+	value := uint64(0)
+	for idx, c := range runes {
+		digit := r.index(c)
+		if digit < 0 {
+			invalid := &InvalidCharacterError{Index: idx, Rune: c}
+			if c < utf8.RuneSelf {
+				invalid.Char = byte(c)
+			}
+			return 0, invalid
+		}
+		if idx == 0 {
+			continue
+		}
+		if value > (math.MaxUint64-uint64(digit))/base {
+			return 0, ErrOverflow
+		}
+		value = value*base + uint64(digit)
+	}
+	value = Scramble(value, uint64(r.BlockSize))
+	if r.checksum(value) != runes[0] {
+		return value, ErrChecksumMismatch
+	}
+	return value, nil
+}
+
+// checksum returns the check character for n. Checksum is limited to the alphabet
+// length so the character is always in the alphabet.
+func (r *RuneEncoder) checksum(n uint64) rune {
+	modulus := uint64(r.Checksum)
+	if modulus == 0 || modulus > uint64(len(r.Alphabet)) {
+		modulus = uint64(len(r.Alphabet))
+	}
+	return r.Alphabet[n%modulus]
+}
+
+// index returns the position of c in the alphabet, or -1
+func (r *RuneEncoder) index(c rune) int {
+	if r.sorted != nil {
+		return r.sorted.index(c)
+	}
+	for idx, a := range r.Alphabet {
+		if a == c {
+			return idx
+		}
+	}
+	return -1
+}
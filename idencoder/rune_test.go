@@ -0,0 +1,45 @@
+package idencoder
+
+import (
+	"errors"
+	"testing"
+)
+
+// testRunes is a multi-byte alphabet of 31 CJK characters
+var testRunes = []rune("日月火水木金土山川田人口目耳手足心天地空雨雪風花草竹石玉糸米虫")
+
+func TestRuneEncoderRoundTrip(t *testing.T) {
+	r := &RuneEncoder{Alphabet: testRunes, BlockSize: DefaultBlockSize, Checksum: DefaultChecksum}
+	for _, minLength := range []uint64{0, 1, MinLength} {
+		for _, n := range []uint64{0, 1, 30, 31, 123456789, 1<<64 - 1} {
+			encoded, err := r.Encode(n, minLength)
+			if err != nil {
+				t.Fatalf("Encode(%d, %d): %v", n, minLength, err)
+			}
+			if got := len([]rune(encoded)); got < 2 || uint64(got) < 1+minLength {
+				t.Errorf("Encode(%d, %d) = %q, %d runes, too short", n, minLength, encoded, got)
+			}
+			if decoded, err := r.Decode(encoded); err != nil || decoded != n {
+				t.Errorf("Decode(%q) = %d, %v, want %d", encoded, decoded, err, n)
+			}
+		}
+	}
+}
+
+func TestRuneEncoderInvalidCharacter(t *testing.T) {
+	r := &RuneEncoder{Alphabet: testRunes, BlockSize: DefaultBlockSize, Checksum: DefaultChecksum}
+	encoded, err := r.Encode(42, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runes := []rune(encoded)
+	runes[3] = '€'
+	_, err = r.Decode(string(runes))
+	var invalid *InvalidCharacterError
+	if !errors.As(err, &invalid) || !errors.Is(err, ErrInvalidCharacter) {
+		t.Fatalf("Decode error %v isn't an InvalidCharacterError", err)
+	}
+	if invalid.Index != 3 || invalid.Rune != '€' {
+		t.Errorf("got character %q at index %d, want '€' at rune index 3", invalid.Rune, invalid.Index)
+	}
+}
@@ -0,0 +1,42 @@
+package idencoder
+
+import "strings"
+
+// maxSafeAttempts bounds how many consecutive values EncodeSafe tries
+const maxSafeAttempts = 1000
+
+// EncodeSafe encodes n like Encode, but if the code contains any Blocklist entry,
+// it moves on to n+1, n+2 and so on until a value encodes to a clean code, and
+// returns that code with the value actually used. The caller must store the
+// returned value, and skip past it when allocating the next one, because this
+// breaks the one-to-one mapping between a counter and its codes. Returns an error
+// if no clean code is found within a bounded number of values.
+func (i *IdEncoder) EncodeSafe(n, minLength uint64) (string, uint64, error) {
+	for attempt := 0; attempt < maxSafeAttempts; attempt++ {
+		encoded, err := i.Encode(n, minLength)
+		if err != nil {
+			return "", n, err
+		}
+		if !i.blocked(encoded) {
+			return encoded, n, nil
+		}
+		if n == ^uint64(0) {
+			break
+		}
+		n++
+	}
+	return "", n, &IdEncoderError{
+		Message: "No unblocked code found",
+	}
+}
+
+// blocked reports whether code contains any Blocklist entry, ignoring case
+func (i *IdEncoder) blocked(code string) bool {
+	code = strings.ToLower(code)
+	for _, word := range i.Blocklist {
+		if word != "" && strings.Contains(code, strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
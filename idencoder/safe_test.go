@@ -0,0 +1,43 @@
+package idencoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeSafe(t *testing.T) {
+	i := testEncoder()
+	blocked, _ := i.Encode(1000, MinLength)
+	// upper case, since matching ignores case
+	i.Blocklist = []string{strings.ToUpper(blocked[1:4]), ""}
+	code, used, err := i.EncodeSafe(1000, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used <= 1000 || strings.Contains(code, blocked[1:4]) {
+		t.Errorf("EncodeSafe(1000) = %q, %d, want a later value without %q", code, used, blocked[1:4])
+	}
+	for n := uint64(1000); n < used; n++ {
+		if skipped, _ := i.Encode(n, MinLength); !strings.Contains(skipped, blocked[1:4]) {
+			t.Errorf("EncodeSafe skipped %d, whose code %q isn't blocked", n, skipped)
+		}
+	}
+	if want, _ := i.Encode(used, MinLength); code != want {
+		t.Errorf("EncodeSafe(1000) = %q for %d, want %q", code, used, want)
+	}
+	if decoded, err := i.Decode(code); err != nil || decoded != used {
+		t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, used)
+	}
+
+	// a clean code is returned as is
+	clean, _ := i.Encode(1, MinLength)
+	if code, used, err := i.EncodeSafe(1, MinLength); err != nil || code != clean || used != 1 {
+		t.Errorf("EncodeSafe(1) = %q, %d, %v, want %q, 1", code, used, err, clean)
+	}
+
+	// blocking every character leaves nothing to return
+	i.Blocklist = strings.Split(DefaultAlphabet, "")
+	if code, _, err := i.EncodeSafe(1, MinLength); err == nil {
+		t.Errorf("EncodeSafe(1) = %q with every character blocked", code)
+	}
+}
@@ -0,0 +1,18 @@
+package idencoder
+
+// Split separates an encoded value into its checksum character and data characters,
+// padding included, without decoding the integer or verifying the checksum. The
+// prefix, separators and decoy characters are removed first, and a trailing or
+// interleaved checksum is moved back to the front, so the pieces are those of the raw layout:
+// checksum first, then data, which is empty for zero encoded with no padding.
+// Returns ErrTooShort if s has no checksum character.
+func (i *IdEncoder) Split(s string) (checksum byte, data []byte, err error) {
+	raw, err := i.parse(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(raw) < 1 {
+		return 0, nil, ErrTooShort
+	}
+	return raw[0], []byte(raw[1:]), nil
+}
@@ -0,0 +1,40 @@
+package idencoder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	plain := testEncoder()
+	formatted := testEncoder()
+	formatted.Prefix = "id_"
+	formatted.GroupSize = 2
+	formatted.DecoyPrefixLen = 2
+	formatted.ChecksumPlacement = ChecksumTrailing
+	for _, n := range []uint64{0, 1000, 123456789} {
+		raw, err := plain.Encode(n, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range []*IdEncoder{plain, formatted} {
+			code, _ := i.Encode(n, 6)
+			checksum, data, err := i.Split(code)
+			if err != nil || checksum != raw[0] || string(data) != raw[1:] {
+				t.Errorf("Split(%q) = %q, %q, %v, want %q, %q", code, checksum, data, err, raw[0], raw[1:])
+			}
+		}
+	}
+	// the checksum isn't verified
+	code, _ := plain.Encode(1000, 6)
+	bad := string(plain.Alphabet[(plain.digit(code[0])+1)%int(plain.Checksum)]) + code[1:]
+	if checksum, data, err := plain.Split(bad); err != nil || checksum != bad[0] || string(data) != code[1:] {
+		t.Errorf("Split(%q) = %q, %q, %v, want %q, %q", bad, checksum, data, err, bad[0], code[1:])
+	}
+	if checksum, data, err := plain.Split(code[:1]); err != nil || checksum != code[0] || len(data) != 0 {
+		t.Errorf("Split(%q) = %q, %q, %v, want %q and no data", code[:1], checksum, data, err, code[0])
+	}
+	if _, _, err := plain.Split(""); !errors.Is(err, ErrTooShort) {
+		t.Errorf("Split(\"\") error %v, want ErrTooShort", err)
+	}
+}
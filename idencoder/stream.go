@@ -0,0 +1,87 @@
+package idencoder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// StreamMode selects what StreamNDJSON does with each line
+type StreamMode int
+
+const (
+	// StreamEncode reads {"value":N} lines and writes {"code":"..."} lines
+	StreamEncode StreamMode = 1 << iota
+	// StreamDecode reads {"code":"..."} lines and writes {"value":N} lines
+	StreamDecode
+	// StreamStrict stops at the first line that fails, after reporting it
+	StreamStrict
+)
+
+type streamValue struct {
+	Value uint64 `json:"value"`
+}
+
+type streamCode struct {
+	Code string `json:"code"`
+}
+
+type streamError struct {
+	Error string `json:"error"`
+	Line  int    `json:"line"`
+}
+
+// StreamNDJSON encodes or decodes newline-delimited JSON from r to w one line at a
+// time, writing one output line per non-blank input line. A line that fails is
+// reported as {"error":"...","line":k}, counting lines from 1, and processing
+// continues unless mode includes StreamStrict, in which case the error is returned.
+// Encoding uses the encoder's MinLength. Errors reading r or writing w are returned.
+func (i *IdEncoder) StreamNDJSON(r io.Reader, w io.Writer, mode StreamMode) error {
+	scanner := bufio.NewScanner(r)
+	enc := json.NewEncoder(w)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		out, err := i.streamLine(text, mode)
+		if err != nil {
+			if writeErr := enc.Encode(streamError{Error: err.Error(), Line: line}); writeErr != nil {
+				return writeErr
+			}
+			if mode&StreamStrict != 0 {
+				return err
+			}
+			continue
+		}
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// streamLine processes a single line of StreamNDJSON input, returning the record to write
+func (i *IdEncoder) streamLine(text []byte, mode StreamMode) (interface{}, error) {
+	if mode&StreamDecode != 0 {
+		var req streamCode
+		if err := decodeJSONRequest(bytes.NewReader(text), &req); err != nil {
+			return nil, err
+		}
+		decoded, err := i.Decode(req.Code)
+		if err != nil {
+			return nil, err
+		}
+		return streamValue{Value: decoded}, nil
+	}
+	var req streamValue
+	if err := decodeJSONRequest(bytes.NewReader(text), &req); err != nil {
+		return nil, err
+	}
+	encoded, err := i.Encode(req.Value, i.minLength())
+	if err != nil {
+		return nil, err
+	}
+	return streamCode{Code: encoded}, nil
+}
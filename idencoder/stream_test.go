@@ -0,0 +1,61 @@
+package idencoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStreamNDJSON(t *testing.T) {
+	i := testEncoder()
+	a, _ := i.Encode(1, i.minLength())
+	b, _ := i.Encode(1000, i.minLength())
+	tests := []struct {
+		name  string
+		mode  StreamMode
+		input string
+		want  []string
+		err   bool
+	}{
+		{
+			"encode", StreamEncode,
+			"{\"value\":1}\n{\"value\":\n\n{\"value\":1000}\n",
+			[]string{`{"code":"` + a + `"}`, `{"error":`, `{"code":"` + b + `"}`},
+			false,
+		},
+		{
+			"decode", StreamDecode,
+			fmt.Sprintf("{\"code\":%q}\n{\"code\":\"!\"}\n{\"code\":%q}\n", a, b),
+			[]string{`{"value":1}`, `{"error":`, `{"value":1000}`},
+			false,
+		},
+		{
+			"strict", StreamEncode | StreamStrict,
+			"{\"value\":1}\nnot json\n{\"value\":1000}\n",
+			[]string{`{"code":"` + a + `"}`, `{"error":`},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := i.StreamNDJSON(strings.NewReader(tt.input), &out, tt.mode)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: StreamNDJSON error %v, want an error: %t", tt.name, err, tt.err)
+		}
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != len(tt.want) {
+			t.Errorf("%s: got %d lines %q, want %d", tt.name, len(lines), lines, len(tt.want))
+			continue
+		}
+		for idx, want := range tt.want {
+			if !strings.HasPrefix(lines[idx], want) {
+				t.Errorf("%s: line %d = %s, want %s", tt.name, idx+1, lines[idx], want)
+			}
+		}
+		// the failing input is line 2 in every test
+		if !strings.HasSuffix(lines[1], `"line":2}`) {
+			t.Errorf("%s: error line %s doesn't report input line 2", tt.name, lines[1])
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package idencoder
+
+import "time"
+
+// EncodeTime encodes t as milliseconds since the Unix epoch. Returns an error for
+// times before the epoch.
+//
+// Codes sort lexically in time order only when nothing else reorders them: a
+// BlockSize of 0, no Salt or scramble key, an alphabet in ascending byte order,
+// ChecksumTrailing so the checksum doesn't lead, and a minLength at least the
+// width of the latest time encoded, since a shorter base-N code sorts after a
+// longer one that starts with a lower character.
+func (i *IdEncoder) EncodeTime(t time.Time, minLength uint64) (string, error) {
+	// UnixNano overflows after 2262, so milliseconds are built from seconds
+	ms := t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+	if ms < 0 {
+		return "", &IdEncoderError{
+			Message: "Time must not be before the Unix epoch",
+		}
+	}
+	return i.Encode(uint64(ms), minLength)
+}
+
+// DecodeTime decodes a value encoded by EncodeTime to a UTC time with millisecond precision
+func (i *IdEncoder) DecodeTime(s string) (time.Time, error) {
+	ms, err := i.Decode(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ms > 1<<63-1 {
+		return time.Time{}, ErrOverflow
+	}
+	return time.Unix(int64(ms/1000), int64(ms%1000)*int64(time.Millisecond)).UTC(), nil
+}
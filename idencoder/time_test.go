@@ -0,0 +1,65 @@
+package idencoder
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestEncodeTime(t *testing.T) {
+	i := testEncoder()
+	for _, tm := range []time.Time{
+		time.Unix(0, 0),
+		time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.UTC),
+		time.Date(2100, 12, 31, 23, 59, 59, 999000000, time.FixedZone("EST", -5*3600)),
+		// beyond the range of UnixNano
+		time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(9999, 12, 31, 23, 59, 59, 999000000, time.UTC),
+	} {
+		code, err := i.EncodeTime(tm, MinLength)
+		if err != nil {
+			t.Fatalf("EncodeTime(%v): %v", tm, err)
+		}
+		decoded, err := i.DecodeTime(code)
+		if err != nil || !decoded.Equal(tm) || decoded.Location() != time.UTC {
+			t.Errorf("DecodeTime(%q) = %v, %v, want %v in UTC", code, decoded, err, tm)
+		}
+	}
+	// precision is milliseconds
+	tm := time.Date(2021, 3, 4, 5, 6, 7, 8999999, time.UTC)
+	code, _ := i.EncodeTime(tm, MinLength)
+	if decoded, err := i.DecodeTime(code); err != nil || !decoded.Equal(tm.Truncate(time.Millisecond)) {
+		t.Errorf("DecodeTime(%q) = %v, %v, want %v", code, decoded, err, tm.Truncate(time.Millisecond))
+	}
+	if _, err := i.EncodeTime(time.Unix(-1, 0), MinLength); err == nil {
+		t.Error("EncodeTime accepted a time before the epoch")
+	}
+	code, _ = i.Encode(1<<64-1, MinLength)
+	if _, err := i.DecodeTime(code); !errors.Is(err, ErrOverflow) {
+		t.Errorf("DecodeTime(%q) error %v, want ErrOverflow", code, err)
+	}
+}
+
+func TestEncodeTimeSorts(t *testing.T) {
+	alphabet := []byte(DefaultAlphabet)
+	sort.Slice(alphabet, func(a, b int) bool { return alphabet[a] < alphabet[b] })
+	i := testEncoder()
+	i.Alphabet = Alphabet(alphabet)
+	i.BlockSize = 0
+	i.ChecksumPlacement = ChecksumTrailing
+	const minLength = 10
+
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	var codes []string
+	for _, step := range []time.Duration{0, time.Millisecond, time.Second, time.Hour, 24 * time.Hour, 365 * 24 * time.Hour, 50 * 365 * 24 * time.Hour} {
+		code, err := i.EncodeTime(start.Add(step), minLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, code)
+	}
+	if !sort.StringsAreSorted(codes) {
+		t.Errorf("codes for ascending times aren't sorted: %q", codes)
+	}
+}
@@ -0,0 +1,19 @@
+package idencoder
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DecodeURL converts a code taken from a URL to an integer like Decode, first
+// undoing any percent-encoding, such as "%2A" for an alphabet containing '*'.
+// Returns an error if s contains a malformed escape.
+func (i *IdEncoder) DecodeURL(s string) (uint64, error) {
+	unescaped, err := url.PathUnescape(s)
+	if err != nil {
+		return 0, &IdEncoderError{
+			Message: fmt.Sprintf("Invalid URL escape in %q: %v", s, err),
+		}
+	}
+	return i.Decode(unescaped)
+}
@@ -0,0 +1,55 @@
+package idencoder
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDecodeURL(t *testing.T) {
+	i := testEncoder()
+	i.Alphabet = Alphabet("0123456789*+!$&'(),;=")
+	i.Checksum = 19
+	for _, n := range []uint64{0, 1000, 123456789} {
+		code, err := i.Encode(n, MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var escaped strings.Builder
+		for idx := 0; idx < len(code); idx++ {
+			fmt.Fprintf(&escaped, "%%%02X", code[idx])
+		}
+		for _, s := range []string{code, escaped.String()} {
+			if decoded, err := i.DecodeURL(s); err != nil || decoded != n {
+				t.Errorf("DecodeURL(%q) = %d, %v, want %d", s, decoded, err, n)
+			}
+		}
+	}
+	if _, err := i.DecodeURL("12%zz34"); err == nil {
+		t.Error("DecodeURL accepted a malformed escape")
+	}
+}
+
+func TestNewURLSafe(t *testing.T) {
+	i := NewURLSafe()
+	if err := i.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if string(i.Alphabet) == DefaultAlphabet || !isPrime(len(i.Alphabet)) {
+		t.Errorf("NewURLSafe alphabet %q, want a prime length alphabet other than the default", i.Alphabet)
+	}
+	for _, n := range testValues() {
+		code, err := i.Encode(n, i.MinLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// unreserved characters are never escaped
+		if escaped := url.PathEscape(code); escaped != code || url.QueryEscape(code) != code {
+			t.Errorf("Encode(%d) = %q, which escapes to %q", n, code, escaped)
+		}
+		if decoded, err := i.Decode(code); err != nil || decoded != n {
+			t.Errorf("Decode(%q) = %d, %v, want %d", code, decoded, err, n)
+		}
+	}
+}
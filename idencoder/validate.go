@@ -0,0 +1,113 @@
+package idencoder
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Validate reports whether the encoder is configured such that every value can be
+// encoded and decoded: the alphabet must have at least two characters and no
+// duplicates, BlockSize must not exceed 64 bits, ChecksumName must be registered,
+// Checksum must be between 1 and the alphabet length, the group separator must
+// not be in the alphabet, and MinLength must not exceed MaxLength.
+func (i *IdEncoder) Validate() error {
+	if len(i.Alphabet) < 2 {
+		return &IdEncoderError{
+			Message: "Alphabet must contain at least two characters",
+		}
+	}
+	seen := make(map[byte]bool)
+	for _, c := range i.Alphabet {
+		if seen[c] {
+			return &IdEncoderError{
+				Message: fmt.Sprintf("Alphabet contains duplicate character %q", c),
+			}
+		}
+		seen[c] = true
+	}
+	if i.BlockSize > 64 {
+		return &IdEncoderError{
+			Message: "BlockSize must not exceed 64",
+		}
+	}
+	if i.ChecksumName != "" {
+		if _, _, ok := lookupChecksum(i.ChecksumName); !ok {
+			return fmt.Errorf("%w %q", ErrUnknownChecksum, i.ChecksumName)
+		}
+	}
+	if mode, fn := i.checksumAlgorithm(); fn == nil && mode != CheckOutput && (i.Checksum == 0 || uint64(i.Checksum) > uint64(len(i.Alphabet))) {
+		return &IdEncoderError{
+			Message: "Checksum must be between 1 and the alphabet length",
+		}
+	}
+	// the scrambled value modulo a common factor of the radix depends only on its last digits
+	if mode, fn := i.checksumAlgorithm(); fn == nil && mode == CheckScrambled && gcd(uint64(i.Checksum), uint64(len(i.Alphabet))) != 1 {
+		return &IdEncoderError{
+			Message: "Checksum must share no factor with the alphabet length in CheckScrambled mode",
+		}
+	}
+	if i.ZeroSymbol != 0 && (bytes.IndexByte(i.Alphabet, i.ZeroSymbol) >= 0 || i.GroupSize > 0 && i.ZeroSymbol == i.separator()) {
+		return &IdEncoderError{
+			Message: fmt.Sprintf("ZeroSymbol %q must not be in the alphabet or be the separator", i.ZeroSymbol),
+		}
+	}
+	if i.PadChar != 0 {
+		inAlphabet := bytes.IndexByte(i.Alphabet, i.PadChar)
+		if inAlphabet > 0 || i.PadChar == i.ZeroSymbol || i.GroupSize > 0 && i.PadChar == i.separator() {
+			return &IdEncoderError{
+				Message: fmt.Sprintf("PadChar %q must be the first character of the alphabet or unused elsewhere", i.PadChar),
+			}
+		}
+	}
+	if i.DecoyPrefixLen < 0 {
+		return &IdEncoderError{
+			Message: "DecoyPrefixLen must not be negative",
+		}
+	}
+	if i.GroupSize > 0 && bytes.IndexByte(i.Alphabet, i.separator()) >= 0 {
+		return &IdEncoderError{
+			Message: fmt.Sprintf("Separator %q must not be in the alphabet", i.separator()),
+		}
+	}
+	if i.minLength() > i.maxLength() {
+		return &IdEncoderError{
+			Message: "MinLength must not exceed MaxLength",
+		}
+	}
+	return nil
+}
+
+// VerifyNoCollisions encodes, with the encoder's MinLength, and decodes every value in
+// [0, max], returning an error if any value fails to round-trip or two values
+// produce the same encoded value
+func (i *IdEncoder) VerifyNoCollisions(max uint64) error {
+	seen := make(map[string]uint64)
+	for n := uint64(0); ; n++ {
+		encoded, err := i.Encode(n, i.minLength())
+		if err != nil {
+			return err
+		}
+		if decoded, err := i.Decode(encoded); err != nil || decoded != n {
+			return &IdEncoderError{
+				Message: fmt.Sprintf("%d does not round-trip through %q", n, encoded),
+			}
+		}
+		if prev, ok := seen[encoded]; ok {
+			return &IdEncoderError{
+				Message: fmt.Sprintf("%d and %d both encode to %q", prev, n, encoded),
+			}
+		}
+		seen[encoded] = n
+		if n == max {
+			return nil
+		}
+	}
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,221 @@
+package idencoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsValidMatchesDecode(t *testing.T) {
+	strictLength := testEncoder()
+	strictLength.MinLength = 8
+	strictLength.StrictLength = true
+	strictCanonical := testEncoder()
+	strictCanonical.StrictCanonical = true
+	for _, i := range []*IdEncoder{testEncoder(), strictLength, strictCanonical} {
+		for _, n := range []uint64{0, 7, 123456789} {
+			for _, minLength := range []uint64{0, MinLength, 8, 12} {
+				encoded, err := i.Encode(n, minLength)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = i.Decode(encoded)
+				if valid := i.IsValid(encoded); valid != (err == nil) {
+					t.Errorf("IsValid(%q) = %v, but Decode returned %v", encoded, valid, err)
+				}
+				if matches := i.Matches(encoded); matches != (err == nil) {
+					t.Errorf("Matches(%q) = %v, but Decode returned %v", encoded, matches, err)
+				}
+			}
+		}
+	}
+	overPadded, _ := strictLength.Encode(7, 12)
+	if strictLength.IsValid(overPadded) || strictLength.Matches(overPadded) {
+		t.Errorf("%q is accepted despite StrictLength", overPadded)
+	}
+	overPadded, _ = strictCanonical.Encode(7, 8)
+	if strictCanonical.IsValid(overPadded) || strictCanonical.Matches(overPadded) {
+		t.Errorf("%q is accepted despite StrictCanonical", overPadded)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	i := testEncoder()
+	valid, err := i.Encode(123456789, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badChecksum := []byte(valid)
+	for _, c := range i.Alphabet {
+		if c != valid[0] {
+			badChecksum[0] = c
+			break
+		}
+	}
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{valid, true},
+		{valid[:3] + "!" + valid[4:], false},
+		{"!" + valid[1:], false},
+		{string(badChecksum), false},
+		{valid[:1], false},
+		{"", false},
+		{valid + strings.Repeat(string(i.Alphabet[1]), 100), false},
+	}
+	for _, tt := range tests {
+		if got := i.IsValid(tt.s); got != tt.want {
+			t.Errorf("IsValid(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	i := testEncoder()
+	code, err := i.Encode(1000, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := string(i.Alphabet[(i.digit(code[0])+1)%int(i.Checksum)]) + code[1:]
+	for _, tc := range []struct {
+		name string
+		s    string
+		want error
+	}{
+		{"canonical", code, nil},
+		{"checksum", tampered, ErrChecksumMismatch},
+		{"character", code[:2] + "!" + code[3:], ErrInvalidCharacter},
+		{"too long", code + strings.Repeat(code[1:], 20), ErrTooLong},
+		{"padding", code[:1] + string(i.Alphabet[0]) + code[1:], ErrNotCanonical},
+	} {
+		decoded, err := i.DecodeStrict(tc.s)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: DecodeStrict(%q) error %v, want %v", tc.name, tc.s, err, tc.want)
+		}
+		if tc.want == nil && decoded != 1000 {
+			t.Errorf("%s: DecodeStrict(%q) = %d, want 1000", tc.name, tc.s, decoded)
+		}
+	}
+}
+
+func TestDecodeVerify(t *testing.T) {
+	i := testEncoder()
+	code, err := i.Encode(1000, MinLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Checksum 29 leaves the last two alphabet characters out of the checksum range
+	outOfRange := string(i.Alphabet[len(i.Alphabet)-1]) + code[1:]
+	tests := []struct {
+		name       string
+		s          string
+		value      uint64
+		checksumOK bool
+		err        error
+	}{
+		{"valid", code, 1000, true, nil},
+		{"bad checksum", string(i.Alphabet[(i.digit(code[0])+1)%int(i.Checksum)]) + code[1:], 1000, false, nil},
+		{"checksum out of range", outOfRange, 1000, false, nil},
+		{"checksum not in alphabet", "!" + code[1:], 1000, false, nil},
+		{"invalid data character", code[:2] + "!" + code[3:], 0, false, ErrInvalidCharacter},
+		{"too short", "", 0, false, ErrTooShort},
+		{"overflow", code[:1] + strings.Repeat(string(i.Alphabet[len(i.Alphabet)-1]), 14), 0, false, ErrOverflow},
+	}
+	for _, tt := range tests {
+		value, checksumOK, err := i.DecodeVerify(tt.s)
+		if value != tt.value || checksumOK != tt.checksumOK || !errors.Is(err, tt.err) {
+			t.Errorf("%s: DecodeVerify(%q) = %d, %t, %v, want %d, %t, %v", tt.name, tt.s, value, checksumOK, err, tt.value, tt.checksumOK, tt.err)
+		}
+	}
+}
+
+func TestDecodeCanonical(t *testing.T) {
+	i := testEncoder()
+	i.Prefix = "id_"
+	i.GroupSize = 3
+	i.FoldCase = true
+	canonical, err := i.Encode(1000, i.minLength())
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded, _ := i.Encode(1000, 9)
+	for _, s := range []string{canonical, padded, "id_" + strings.ToUpper(canonical[3:]), strings.Replace(canonical, "-", "", -1)} {
+		value, got, err := i.DecodeCanonical(s)
+		if err != nil || value != 1000 || got != canonical {
+			t.Errorf("DecodeCanonical(%q) = %d, %q, %v, want 1000, %q", s, value, got, err, canonical)
+		}
+	}
+	if _, got, err := i.DecodeCanonical("id_!"); err == nil || got != "" {
+		t.Errorf("DecodeCanonical(\"id_!\") = %q, %v, want only an error", got, err)
+	}
+}
+
+func TestStrictCanonical(t *testing.T) {
+	i := testEncoder()
+	i.GroupSize = 3
+	i.FoldCase = true
+	strict := *i
+	strict.StrictCanonical = true
+	for _, n := range []uint64{0, 7, 123456789} {
+		canonical, err := i.Encode(n, i.minLength())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded, err := strict.Decode(canonical); err != nil || decoded != n {
+			t.Errorf("strict Decode(%q) = %d, %v, want %d", canonical, decoded, err, n)
+		}
+		overPadded, _ := i.Encode(n, i.minLength()+2)
+		for _, s := range []string{
+			overPadded,
+			strings.ToUpper(canonical),
+			strings.Replace(canonical, "-", "", -1),
+			canonical[:1] + "-" + canonical[1:],
+		} {
+			if s == canonical {
+				// upper case changes nothing in a code of digits
+				continue
+			}
+			if decoded, err := i.Decode(s); err != nil || decoded != n {
+				t.Errorf("lenient Decode(%q) = %d, %v, want %d", s, decoded, err, n)
+			}
+			if _, err := strict.Decode(s); !errors.Is(err, ErrNotCanonical) {
+				t.Errorf("strict Decode(%q) error %v, want ErrNotCanonical", s, err)
+			}
+		}
+	}
+}
+
+func TestMatchesRejectsOtherAlphabets(t *testing.T) {
+	i := testEncoder()
+	upper := testEncoder()
+	upper.Alphabet = Alphabet("ABCDEFGHJKLMNPQRSTUVWXYZ2345679")
+	shuffled := testEncoder()
+	shuffled.Alphabet = Alphabet(DefaultAlphabet).Shuffle(5)
+	const count = 1000
+	shuffledMatches := 0
+	for n := uint64(0); n < count; n++ {
+		own, _ := i.Encode(n, MinLength)
+		if !i.Matches(own) {
+			t.Errorf("Matches(%q) = false for its own code", own)
+		}
+		other, _ := upper.Encode(n, MinLength)
+		if i.Matches(other) {
+			t.Errorf("Matches(%q) = true for a code from another alphabet", other)
+		}
+		other, _ = shuffled.Encode(n, MinLength)
+		if i.Matches(other) {
+			shuffledMatches++
+		}
+	}
+	// the same characters in another order only pass the checksum by chance, about
+	// once in 29
+	if shuffledMatches > count/10 {
+		t.Errorf("Matches accepted %d of %d codes from a reordered alphabet", shuffledMatches, count)
+	}
+	for _, s := range []string{"", "f", "id_fhqyf7", "fhqyf7-", strings.Repeat("f", 80)} {
+		if i.Matches(s) {
+			t.Errorf("Matches(%q) = true", s)
+		}
+	}
+}
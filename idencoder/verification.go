@@ -0,0 +1,28 @@
+package idencoder
+
+// VerificationCode returns a numeric code of the given number of digits derived
+// from n, for a person to read back to confirm a value, such as at order pickup.
+// It is deterministic but not reversible, and is derived separately from the
+// encoded value, salted, and keyed when a scramble key is set. Returns an empty
+// string if digits isn't positive.
+func (i *IdEncoder) VerificationCode(n uint64, digits int) string {
+	if digits <= 0 {
+		return ""
+	}
+	h := splitmix64(n ^ i.Salt)
+	if i.scrambleKey != nil {
+		h = i.permute(h)
+	}
+	code := make([]byte, digits)
+	x := h
+	for idx := range code {
+		// a uint64 holds 19 full decimal digits, so draw more as needed
+		if idx > 0 && idx%19 == 0 {
+			h = splitmix64(h)
+			x = h
+		}
+		code[idx] = '0' + byte(x%10)
+		x /= 10
+	}
+	return string(code)
+}
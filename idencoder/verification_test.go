@@ -0,0 +1,38 @@
+package idencoder
+
+import "testing"
+
+func TestVerificationCode(t *testing.T) {
+	i := testEncoder()
+	seen := make(map[string]int)
+	for n := uint64(0); n < 1000; n++ {
+		code := i.VerificationCode(n, 4)
+		if len(code) != 4 || code != i.VerificationCode(n, 4) {
+			t.Fatalf("VerificationCode(%d, 4) = %q, then %q", n, code, i.VerificationCode(n, 4))
+		}
+		for idx := 0; idx < len(code); idx++ {
+			if code[idx] < '0' || code[idx] > '9' {
+				t.Fatalf("VerificationCode(%d, 4) = %q isn't numeric", n, code)
+			}
+		}
+		seen[code]++
+	}
+	// 1000 values spread over 10000 codes rarely share one
+	if len(seen) < 900 {
+		t.Errorf("1000 values have only %d distinct verification codes", len(seen))
+	}
+	if i.VerificationCode(1, 6) == i.VerificationCode(2, 6) {
+		t.Error("VerificationCode(1, 6) == VerificationCode(2, 6)")
+	}
+	if long := i.VerificationCode(1, 40); len(long) != 40 {
+		t.Errorf("VerificationCode(1, 40) = %q, want 40 digits", long)
+	}
+	if code := i.VerificationCode(1, 0); code != "" {
+		t.Errorf("VerificationCode(1, 0) = %q, want \"\"", code)
+	}
+	salted := testEncoder()
+	salted.Salt = 99
+	if salted.VerificationCode(1, 6) == i.VerificationCode(1, 6) {
+		t.Error("VerificationCode doesn't depend on Salt")
+	}
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/text/language"
@@ -14,14 +19,198 @@ import (
 	"github.com/brnt/idencoder-go/idencoder"
 )
 
-// randomAlphabet generates a random alphabet, containing the same characters as idencoder.DefaultAlphabet
-func randomAlphabet() string {
-	rand.Seed(time.Now().Unix())
-	aRune := []rune(idencoder.DefaultAlphabet)
-	rand.Shuffle(len(aRune), func(i, j int) {
-		aRune[i], aRune[j] = aRune[j], aRune[i]
-	})
-	return string(aRune)
+// Environment variables consulted when the corresponding flag isn't given, keeping
+// secret encoder settings out of the process arguments
+const (
+	envAlphabet  = "IDENCODER_ALPHABET"
+	envBlockSize = "IDENCODER_BLOCK_SIZE"
+	envChecksum  = "IDENCODER_CHECKSUM"
+)
+
+// setting resolves a configuration value: the flag if given, else the environment variable, else def
+func setting(flag, env, def string) string {
+	if flag != "" {
+		return flag
+	}
+	if value, ok := os.LookupEnv(env); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+// benchmarkBatch is the number of codes benchmarkCSV holds at once, so its memory
+// use doesn't grow with the number of values
+const benchmarkBatch = 4096
+
+// benchmarkCSV writes a CSV row of encode/decode timings to out for each power of
+// ten up to max. Values are encoded and then decoded a batch at a time, and each
+// row is flushed as soon as it is measured.
+func benchmarkCSV(out io.Writer, ie *idencoder.IdEncoder, max int) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"n", "encode_ns_op", "decode_ns_op", "length"}); err != nil {
+		return err
+	}
+	batch := make([]string, 0, benchmarkBatch)
+	for n := uint64(1); n <= uint64(max); n *= 10 {
+		var encodeTime, decodeTime time.Duration
+		for first := uint64(0); first < n; first += benchmarkBatch {
+			batch = batch[:0]
+			start := time.Now()
+			for i := first; i < n && i < first+benchmarkBatch; i++ {
+				e, err := ie.Encode(i, idencoder.MinLength)
+				if err != nil {
+					return err
+				}
+				batch = append(batch, e)
+			}
+			encodeTime += time.Since(start)
+			start = time.Now()
+			for _, e := range batch {
+				if _, err := ie.Decode(e); err != nil {
+					return err
+				}
+			}
+			decodeTime += time.Since(start)
+		}
+		err := w.Write([]string{
+			strconv.FormatUint(n, 10),
+			strconv.FormatInt(encodeTime.Nanoseconds()/int64(n), 10),
+			strconv.FormatInt(decodeTime.Nanoseconds()/int64(n), 10),
+			strconv.Itoa(len(batch[len(batch)-1])),
+		})
+		if err != nil {
+			return err
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transcodeCSV copies CSV from in to out, decoding the given 1-based column of each
+// row with from and re-encoding it with to. Rows that fail are reported on errOut
+// with their row number and copied unchanged. If header is set, the first row is
+// copied as is. Returns the number of rows that failed.
+func transcodeCSV(in io.Reader, out, errOut io.Writer, from, to *idencoder.IdEncoder, column int, minLength uint64, header bool) (int, error) {
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+	w := csv.NewWriter(out)
+	failed := 0
+	for row := 1; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return failed, err
+		}
+		if !header || row > 1 {
+			if err := transcodeField(from, to, record, column, minLength); err != nil {
+				fmt.Fprintf(errOut, "row %d: %v\n", row, err)
+				failed++
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return failed, err
+		}
+	}
+	w.Flush()
+	return failed, w.Error()
+}
+
+// transcodeField replaces the given 1-based column of record with its code re-encoded from from to to
+func transcodeField(from, to *idencoder.IdEncoder, record []string, column int, minLength uint64) error {
+	if column > len(record) {
+		return fmt.Errorf("no column %d", column)
+	}
+	decoded, err := from.Decode(record[column-1])
+	if err != nil {
+		return err
+	}
+	encoded, err := to.Encode(decoded, minLength)
+	if err != nil {
+		return err
+	}
+	record[column-1] = encoded
+	return nil
+}
+
+// setPad sets the pad character from the --pad flag, which must be a single
+// character that the encoder's configuration accepts
+func setPad(ie *idencoder.IdEncoder, pad string) error {
+	if len(pad) != 1 {
+		return errors.New("must be a single character")
+	}
+	ie.PadChar = pad[0]
+	return ie.Validate()
+}
+
+// usageError reports a usage error: in JSON mode as a JSON error on stderr with a
+// non-zero exit, otherwise as the usage text
+func usageError(parser *argparse.Parser, jsonOut bool, msg string) {
+	if jsonOut {
+		jsonError(errors.New(msg))
+	}
+	fmt.Print(parser.Usage(msg))
+}
+
+// printJSON writes v to stdout as a line of JSON
+func printJSON(v interface{}) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		jsonError(err)
+	}
+}
+
+// jsonError writes err to stderr as a line of JSON and exits with a non-zero status
+func jsonError(err error) {
+	json.NewEncoder(os.Stderr).Encode(map[string]string{"error": err.Error()})
+	os.Exit(1)
+}
+
+// selftestValues is the number of values round-tripped by --selftest
+const selftestValues = 100000
+
+// urlSafe lists the characters that need no escaping anywhere in a URL
+const urlSafe = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+// selftest checks the encoder configuration end-to-end, printing a report, and
+// returns false if any check failed
+func selftest(ie *idencoder.IdEncoder) bool {
+	ok := true
+	report := func(status, check string, err error) {
+		if err != nil {
+			fmt.Printf("%-4s %s: %v\n", status, check, err)
+			if status == "FAIL" {
+				ok = false
+			}
+			return
+		}
+		fmt.Printf("%-4s %s\n", "PASS", check)
+	}
+	invalid := ie.Validate()
+	report("FAIL", "configuration", invalid)
+	var unsafe error
+	for _, c := range ie.Alphabet {
+		if !strings.ContainsRune(urlSafe, rune(c)) {
+			unsafe = fmt.Errorf("character %q must be escaped in URLs", c)
+			break
+		}
+	}
+	report("FAIL", "URL-safe alphabet", unsafe)
+	var composite error
+	for d := 2; d*d <= len(ie.Alphabet); d++ {
+		if len(ie.Alphabet)%d == 0 {
+			composite = fmt.Errorf("length %d is not prime", len(ie.Alphabet))
+			break
+		}
+	}
+	report("WARN", "prime length alphabet", composite)
+	if invalid == nil {
+		report("FAIL", fmt.Sprintf("round-trip of %d values", selftestValues), ie.VerifyNoCollisions(selftestValues-1))
+	}
+	return ok
 }
 
 func main() {
@@ -29,8 +218,17 @@ func main() {
 	var alphabet *string = parser.String("a", "alphabet",
 		&argparse.Options{
 			Required: false,
-			Default:  idencoder.DefaultAlphabet,
-			Help:     "use ALPHA as the alphabet",
+			Help:     "use ALPHA as the alphabet (default $" + envAlphabet + ", then the built-in default)",
+		})
+	var blockSize *string = parser.String("", "block-size",
+		&argparse.Options{
+			Required: false,
+			Help:     "shuffle NUM bits (default $" + envBlockSize + ", then the built-in default)",
+		})
+	var checksum *string = parser.String("", "checksum",
+		&argparse.Options{
+			Required: false,
+			Help:     "use NUM as the checksum modulus (default $" + envChecksum + ", then the built-in default)",
 		})
 	var quiet *bool = parser.Flag("q", "quiet",
 		&argparse.Options{
@@ -43,7 +241,12 @@ func main() {
 			Default:  idencoder.MinLength,
 			Help:     "set min encoded output length to NUM",
 		})
-	var encode *int = parser.Int("e", "encode",
+	var pad *string = parser.String("", "pad",
+		&argparse.Options{
+			Required: false,
+			Help:     "pad to the min length with CHAR, which must be the first alphabet character or not in the alphabet",
+		})
+	var encode *string = parser.String("e", "encode",
 		&argparse.Options{
 			Required: false,
 			Help:     "encode NUM",
@@ -58,11 +261,52 @@ func main() {
 			Required: false,
 			Help:     "run a series of NUM encode/decode cycles",
 		})
+	var benchmarkCsv *int = parser.Int("", "benchmark-csv",
+		&argparse.Options{
+			Required: false,
+			Help:     "write CSV encode/decode timings for each power of ten up to NUM",
+		})
+	var transcodeCsv *bool = parser.Flag("", "transcode-csv",
+		&argparse.Options{
+			Required: false,
+			Help:     "copy CSV from stdin to stdout, re-encoding the codes in --column from --from-alphabet to --to-alphabet",
+		})
+	var column *int = parser.Int("", "column",
+		&argparse.Options{
+			Required: false,
+			Default:  1,
+			Help:     "transcode the codes in CSV column NUM, counting from 1",
+		})
+	var fromAlphabet *string = parser.String("", "from-alphabet",
+		&argparse.Options{
+			Required: false,
+			Help:     "decode CSV codes with ALPHA (default the --alphabet setting)",
+		})
+	var toAlphabet *string = parser.String("", "to-alphabet",
+		&argparse.Options{
+			Required: false,
+			Help:     "re-encode CSV codes with ALPHA",
+		})
+	var csvHeader *bool = parser.Flag("", "csv-header",
+		&argparse.Options{
+			Required: false,
+			Help:     "copy the first CSV row unchanged",
+		})
 	var random *bool = parser.Flag("r", "random",
 		&argparse.Options{
 			Required: false,
 			Help:     "print a random alphabet",
 		})
+	var selftestFlag *bool = parser.Flag("", "selftest",
+		&argparse.Options{
+			Required: false,
+			Help:     "check the alphabet, block size and checksum, exiting non-zero on failure",
+		})
+	var jsonOut *bool = parser.Flag("", "json",
+		&argparse.Options{
+			Required: false,
+			Help:     "print encode, decode and random results as JSON, and errors as JSON on stderr",
+		})
 
 	err := parser.Parse(os.Args)
 	if err != nil {
@@ -72,24 +316,99 @@ func main() {
 		return
 	}
 
+	bs, err := strconv.ParseUint(setting(*blockSize, envBlockSize, strconv.Itoa(idencoder.DefaultBlockSize)), 10, 64)
+	if err != nil {
+		usageError(parser, *jsonOut, "Invalid block size: "+err.Error())
+		return
+	}
+	cs, err := strconv.ParseUint(setting(*checksum, envChecksum, strconv.Itoa(idencoder.DefaultChecksum)), 10, 64)
+	if err != nil {
+		usageError(parser, *jsonOut, "Invalid checksum: "+err.Error())
+		return
+	}
 	ie := idencoder.IdEncoder{
-		Alphabet:  []byte(*alphabet),
-		BlockSize: idencoder.DefaultBlockSize,
-		Checksum:  idencoder.DefaultChecksum,
+		Alphabet:  []byte(setting(*alphabet, envAlphabet, idencoder.DefaultAlphabet)),
+		BlockSize: idencoder.BlockSize(bs),
+		Checksum:  idencoder.Checksum(cs),
+	}
+	if *pad != "" {
+		if err := setPad(&ie, *pad); err != nil {
+			usageError(parser, *jsonOut, "Invalid pad: "+err.Error())
+			return
+		}
 	}
 	switch true {
-	case *encode > 0:
-		encoded, err := ie.Encode(uint64(*encode), uint64(*length))
+	case *selftestFlag:
+		if !selftest(&ie) {
+			os.Exit(1)
+		}
+	case *transcodeCsv:
+		if *toAlphabet == "" || *column < 1 {
+			usageError(parser, *jsonOut, "Transcoding needs --to-alphabet and a --column of at least 1")
+			return
+		}
+		from, to := ie, ie
+		if *fromAlphabet != "" {
+			from.Alphabet = []byte(*fromAlphabet)
+		}
+		to.Alphabet = []byte(*toAlphabet)
+		if err := to.Validate(); err != nil {
+			usageError(parser, *jsonOut, "Invalid to-alphabet: "+err.Error())
+			return
+		}
+		failed, err := transcodeCSV(os.Stdin, os.Stdout, os.Stderr, &from, &to, *column, uint64(*length), *csvHeader)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "**ERROR** during transcode:", err)
+			os.Exit(1)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	case *encode != "":
+		n, err := strconv.ParseUint(*encode, 10, 64)
+		if err != nil {
+			usageError(parser, *jsonOut, "Invalid value to encode: "+err.Error())
+			return
+		}
+		encoded, err := ie.Encode(n, uint64(*length))
+		if *jsonOut {
+			if err != nil {
+				jsonError(err)
+			}
+			printJSON(map[string]interface{}{"encoded": encoded})
+			return
+		}
 		if err != nil {
 			fmt.Println("**ERROR** during encode")
 		}
 		fmt.Println(encoded)
-	case len(*decode) >= *length:
+	case *decode != "":
 		decoded, err := ie.Decode(*decode)
+		if *jsonOut {
+			if err != nil && !errors.Is(err, idencoder.ErrChecksumMismatch) {
+				jsonError(err)
+			}
+			printJSON(map[string]interface{}{"decoded": decoded, "checksumOK": err == nil})
+			if err != nil {
+				os.Exit(1)
+			}
+			return
+		}
+		if err != nil && !errors.Is(err, idencoder.ErrChecksumMismatch) {
+			fmt.Println("**ERROR** during decode:", err)
+			os.Exit(1)
+		}
+		switch {
+		case *quiet:
+			fmt.Println(decoded)
+		case err != nil:
+			fmt.Printf("decoded: %d\nWARNING: checksum mismatch\n", decoded)
+		default:
+			fmt.Printf("decoded: %d (checksum OK)\n", decoded)
+		}
 		if err != nil {
-			fmt.Println("**ERROR** during decode")
+			os.Exit(1)
 		}
-		fmt.Println(decoded)
 	case *benchmark > 0:
 		start := time.Now().UnixNano()
 		for i := uint64(0); i < uint64(*benchmark); i++ {
@@ -107,15 +426,21 @@ func main() {
 		end := time.Now().UnixNano()
 		p := message.NewPrinter(language.English)
 		p.Printf("BENCHMARK: Ran %d iterations in %0.3f seconds\n", *benchmark, float64(end-start)/1000000000)
+	case *benchmarkCsv > 0:
+		if err := benchmarkCSV(os.Stdout, &ie, *benchmarkCsv); err != nil {
+			fmt.Println("**ERROR** during benchmark:", err)
+		}
 	case *random:
-		alpha := randomAlphabet()
-		if *quiet {
+		alpha := string(idencoder.RandomAlphabet())
+		if *jsonOut {
+			printJSON(map[string]interface{}{"alphabet": alpha})
+		} else if *quiet {
 			fmt.Println(alpha)
 		} else {
 			fmt.Println("Random alphabet:", alpha)
 		}
 	default:
-		fmt.Print(parser.Usage("Must select one of encode, decode, random, or benchmark"))
+		usageError(parser, *jsonOut, "Must select one of encode, decode, random, or benchmark")
 	}
 
 }
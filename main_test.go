@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/brnt/idencoder-go/idencoder"
+)
+
+func TestSettingPrecedence(t *testing.T) {
+	const env = "IDENCODER_TEST_SETTING"
+	defer os.Unsetenv(env)
+	os.Unsetenv(env)
+	if got := setting("", env, "default"); got != "default" {
+		t.Errorf("without flag or environment, got %q, want the default", got)
+	}
+	os.Setenv(env, "")
+	if got := setting("", env, "default"); got != "default" {
+		t.Errorf("with an empty environment variable, got %q, want the default", got)
+	}
+	os.Setenv(env, "environment")
+	if got := setting("", env, "default"); got != "environment" {
+		t.Errorf("with the environment variable set, got %q, want it", got)
+	}
+	if got := setting("flag", env, "default"); got != "flag" {
+		t.Errorf("with a flag and the environment variable, got %q, want the flag", got)
+	}
+}
+
+func TestSetPad(t *testing.T) {
+	for _, tt := range []struct {
+		pad string
+		ok  bool
+	}{
+		{".", true},
+		{idencoder.DefaultAlphabet[:1], true},
+		{idencoder.DefaultAlphabet[1:2], false},
+		{"..", false},
+	} {
+		ie := idencoder.IdEncoder{
+			Alphabet:  []byte(idencoder.DefaultAlphabet),
+			BlockSize: idencoder.DefaultBlockSize,
+			Checksum:  idencoder.DefaultChecksum,
+		}
+		err := setPad(&ie, tt.pad)
+		if (err == nil) != tt.ok {
+			t.Errorf("setPad(%q) error %v, want success: %t", tt.pad, err, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		encoded, err := ie.Encode(7, 10)
+		if err != nil || encoded[1] != tt.pad[0] {
+			t.Errorf("with --pad %q, Encode(7, 10) = %q, %v, want it padded with %q", tt.pad, encoded, err, tt.pad)
+		}
+		if decoded, err := ie.Decode(encoded); err != nil || decoded != 7 {
+			t.Errorf("with --pad %q, Decode(%q) = %d, %v, want 7", tt.pad, encoded, decoded, err)
+		}
+	}
+}
+
+func TestTranscodeCSV(t *testing.T) {
+	from, err := idencoder.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := idencoder.New(idencoder.WithPrefix("x-"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := func(ie *idencoder.IdEncoder, n uint64) string {
+		encoded, err := ie.Encode(n, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+	in := "name,id,note\n" +
+		"a," + code(from, 1) + ",first\n" +
+		"b,bogus,second\n" +
+		"c," + code(from, 1000) + ",\"third, quoted\"\n"
+	want := "name,id,note\n" +
+		"a," + code(to, 1) + ",first\n" +
+		"b,bogus,second\n" +
+		"c," + code(to, 1000) + ",\"third, quoted\"\n"
+
+	var out, errOut bytes.Buffer
+	failed, err := transcodeCSV(strings.NewReader(in), &out, &errOut, from, to, 2, 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if !strings.HasPrefix(errOut.String(), "row 3: ") || strings.Count(errOut.String(), "\n") != 1 {
+		t.Errorf("stderr = %q, want a single report for row 3", errOut.String())
+	}
+
+	// Without header, the header row is itself a failure
+	out.Reset()
+	errOut.Reset()
+	failed, err = transcodeCSV(strings.NewReader(in), &out, &errOut, from, to, 2, 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 2 || !strings.HasPrefix(errOut.String(), "row 1: ") {
+		t.Errorf("without header, failed = %d, stderr = %q, want rows 1 and 3 reported", failed, errOut.String())
+	}
+}
+
+func TestBenchmarkCSV(t *testing.T) {
+	ie, err := idencoder.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	// 10000 values span more than one batch
+	if err := benchmarkCSV(&out, ie, 10000); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 6 || strings.Join(rows[0], ",") != "n,encode_ns_op,decode_ns_op,length" {
+		t.Fatalf("rows = %q, want a header and rows for 1 to 10000", rows)
+	}
+	for k, row := range rows[1:] {
+		n, _ := strconv.ParseUint(row[0], 10, 64)
+		last, _ := ie.Encode(n-1, idencoder.MinLength)
+		if want := uint64(math.Pow10(k)); n != want || row[3] != strconv.Itoa(len(last)) {
+			t.Errorf("row %q, want n %d and length %d", row, want, len(last))
+		}
+	}
+}
+
+// runMain runs main in a subprocess with args, returning its stdout, stderr and
+// exit code
+func runMain(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	cmd := exec.Command(os.Args[0], "-test.run=TestMainProcess")
+	cmd.Env = append(os.Environ(), "IDENCODER_TEST_MAIN="+strings.Join(args, "\n"))
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+// TestMainProcess isn't a test: it runs main for runMain
+func TestMainProcess(t *testing.T) {
+	args, ok := os.LookupEnv("IDENCODER_TEST_MAIN")
+	if !ok {
+		return
+	}
+	os.Args = append([]string{"idencoder"}, strings.Split(args, "\n")...)
+	main()
+	os.Exit(0)
+}
+
+func TestJSONDispatch(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stdout string
+		error  bool
+	}{
+		{[]string{"--json", "-e", "0"}, `{"encoded":"333333"}`, false},
+		{[]string{"--json", "-d", "333333"}, `{"checksumOK":true,"decoded":0}`, false},
+		// a code shorter than --length is still decoded
+		{[]string{"--json", "-d", "ab"}, `{"checksumOK":false,"decoded":524288}`, false},
+		{[]string{"--json", "-e", "-5"}, "", true},
+		{[]string{"--json", "-d", "3!"}, "", true},
+		{[]string{"--json"}, "", true},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runMain(t, tt.args...)
+		if strings.TrimSpace(stdout) != tt.stdout {
+			t.Errorf("%q: stdout %q, want %q", tt.args, stdout, tt.stdout)
+		}
+		if tt.error && (code == 0 || !strings.HasPrefix(stderr, `{"error":`)) {
+			t.Errorf("%q: exit %d, stderr %q, want a JSON error and a non-zero exit", tt.args, code, stderr)
+		}
+		if !tt.error && stderr != "" {
+			t.Errorf("%q: stderr %q, want none", tt.args, stderr)
+		}
+	}
+}